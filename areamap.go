@@ -0,0 +1,108 @@
+package cible
+
+// NewAreaMap returns a map of the area with grid positions derived
+// from the tile links.
+func NewAreaMap(a *Area) *AreaMap {
+	grid := a.Grid()
+	m := &AreaMap{
+		Ident: a.Ident,
+		Title: a.Title,
+		Tiles: make([]MapTile, 0, len(a.Tiles)),
+	}
+	for _, t := range a.Tiles {
+		m.Tiles = append(m.Tiles, MapTile{
+			Ident:    t.Ident,
+			Short:    t.Short,
+			Nav:      t.Nav,
+			Doors:    t.Doors,
+			Position: grid[t.Ident],
+		})
+	}
+	return m
+}
+
+type AreaMap struct {
+	Ident
+	Title
+	Tiles []MapTile
+}
+
+type MapTile struct {
+	Ident
+	Short
+	Nav
+	Doors
+	Position
+}
+
+// Position on a grid, x grows east and y grows south.
+type Position struct {
+	X, Y int
+}
+
+// Move returns the position one step in the given direction.
+func (p Position) Move(d Direction) Position {
+	v := delta[d]
+	return Position{p.X + v.X, p.Y + v.Y}
+}
+
+var delta = [8]Position{
+	N:  {0, -1},
+	NE: {1, -1},
+	E:  {1, 0},
+	SE: {1, 1},
+	S:  {0, 1},
+	SW: {-1, 1},
+	W:  {-1, 0},
+	NW: {-1, -1},
+}
+
+// Grid derives a position for each tile by following the links from
+// the first tile. Tiles that cannot be reached are placed east of
+// the others. If two tiles end up on the same position the first
+// one found keeps it.
+func (a *Area) Grid() map[Ident]Position {
+	grid := make(map[Ident]Position)
+	taken := make(map[Position]bool)
+	var maxX int
+	for _, start := range a.Tiles {
+		if _, found := grid[start.Ident]; found {
+			continue
+		}
+		if len(grid) > 0 {
+			maxX += 2
+		}
+		p := Position{X: maxX}
+		grid[start.Ident] = p
+		taken[p] = true
+		queue := []*Tile{start}
+		for len(queue) > 0 {
+			t := queue[0]
+			queue = queue[1:]
+			here := grid[t.Ident]
+			if here.X > maxX {
+				maxX = here.X
+			}
+			for d, id := range t.Nav {
+				if id == "" {
+					continue
+				}
+				if _, found := grid[id]; found {
+					continue
+				}
+				next, err := a.Tile(id)
+				if err != nil {
+					continue
+				}
+				p := here.Move(Direction(d))
+				if taken[p] {
+					continue
+				}
+				grid[id] = p
+				taken[p] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return grid
+}
@@ -1,6 +1,6 @@
 package cible
 
-import "strings"
+import "fmt"
 
 type Character struct {
 	Ident
@@ -8,29 +8,55 @@ type Character struct {
 	Location
 	IsBot
 	Inventory
+	Stats   // base, see Game.Stats for effective
+	Visited []Location
+	Quests  []QuestProgress
 
-	tr Transmitter // set by server for communication
+	Tuned bool // listening to the global channel
+
+	out *Outbox // set by game if the player is connected
 }
 
+// Transmit queues the message for the player, messages are received
+// in the order they are transmitted.
 func (me *Character) Transmit(m Message) error {
-	if me.tr == nil { // ie. if bot
+	if me.out == nil { // ie. if bot
 		return nil
 	}
-	return me.tr.Transmit(m)
+	return me.out.Send(m)
 }
 
 func (me *Character) TransmitOthers(g *Game, m Message) error {
 	nearby := g.Characters.At(me.Location)
+	s := m.String()
 	for _, c := range nearby {
 		if c.Ident == me.Ident {
 			continue
 		}
-		g.Logf("transmit %s to %s", m.String(), c.Ident)
+		g.Logf("transmit %s to %s", s, c.Ident)
 		c.Transmit(m)
 	}
 	return nil
 }
 
+func (me *Character) closeOutbox() {
+	if me.out != nil {
+		me.out.Close()
+	}
+}
+
+// Visit records the location as visited and returns true if it was
+// the first time.
+func (me *Character) Visit(loc Location) bool {
+	for _, v := range me.Visited {
+		if v.Equal(loc) {
+			return false
+		}
+	}
+	me.Visited = append(me.Visited, loc)
+	return true
+}
+
 type Player struct {
 	Name
 }
@@ -50,15 +76,15 @@ func NewInventory() *Inventory {
 	return &Inventory{
 		Items: Items{
 			&Item{
-				Name:  "credit",
+				Name:  Credit,
 				Count: 200,
 			},
 			&Item{
-				Name:  "Communicator",
+				Name:  "communicator",
 				Count: 1,
 			},
 			&Item{
-				Name:  "Digipass",
+				Name:  "digipass",
 				Count: 1,
 			},
 		},
@@ -69,14 +95,41 @@ type Inventory struct {
 	Items
 }
 
+// AddItem adds the item to the inventory, items with the same name
+// are counted together.
 func (me *Inventory) AddItem(v Item) {
-	v.Name = Name(strings.Title(string(v.Name)))
-	for i, item := range me.Items {
-		if item.Name == v.Name {
-			me.Items[i].Count++
-			return
-		}
+	if v.Count == 0 {
+		v.Count = 1
+	}
+	v.Location = Location{}
+	v.Equipped = false
+	// containers are kept apart so contents don't mix
+	if item, err := me.Items.FindByName(v.Name); err == nil && item.Contents == nil && v.Contents == nil {
+		item.Count += v.Count
+		return
 	}
-
 	me.Items = append(me.Items, &v)
 }
+
+// RemoveItem removes count of the named item, the item is removed
+// completely if none are left.
+func (me *Inventory) RemoveItem(n Name, count uint) error {
+	item, err := me.Items.FindByName(n)
+	if err != nil || item.Count < count {
+		return fmt.Errorf("you do not have %v %s", count, n)
+	}
+	item.Count -= count
+	if item.Count == 0 {
+		me.Items.Remove(item)
+	}
+	return nil
+}
+
+// Count returns number of the named item in the inventory.
+func (me *Inventory) Count(n Name) uint {
+	item, err := me.Items.FindByName(n)
+	if err != nil {
+		return 0
+	}
+	return item.Count
+}
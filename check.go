@@ -0,0 +1,149 @@
+package cible
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckWorld loads a game and returns all problems found in its
+// world definition. Panics while loading, e.g. from Tile.Link, are
+// reported as problems.
+func CheckWorld(load func() *Game) (problems []error) {
+	defer func() {
+		if e := recover(); e != nil {
+			problems = append(problems, fmt.Errorf("load: %v", e))
+		}
+	}()
+	g := load()
+	return g.Check()
+}
+
+// Check returns problems found in the world and the items placed in
+// it.
+func (g *Game) Check() []error {
+	var problems []error
+	report := func(format string, v ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, v...))
+	}
+
+	areas := make(map[Ident]bool)
+	for _, a := range g.Areas {
+		if areas[a.Ident] {
+			report("%s: duplicate area ident", a.Ident)
+		}
+		areas[a.Ident] = true
+		if a.Title == "" {
+			report("%s: empty title", a.Ident)
+		}
+
+		tiles := make(map[Ident]*Tile)
+		for _, t := range a.Tiles {
+			if _, found := tiles[t.Ident]; found {
+				report("%s/%s: duplicate tile ident", a.Ident, t.Ident)
+				continue
+			}
+			tiles[t.Ident] = t
+		}
+
+		for _, t := range a.Tiles {
+			at := fmt.Sprintf("%s/%s", a.Ident, t.Ident)
+			if strings.TrimSpace(string(t.Short)) == "" {
+				report("%s: empty short description", at)
+			}
+			if strings.TrimSpace(string(t.Long)) == "" {
+				report("%s: empty long description", at)
+			}
+			if _, err := t.Describe(TileState{}); err != nil {
+				report("%s: long description %v", at, err)
+			}
+			checkScripts(report, at, t.Scripts)
+			for d, id := range t.Nav {
+				if id == "" {
+					continue
+				}
+				dir := Direction(d)
+				next, found := tiles[id]
+				if !found {
+					report("%s: %s leads to missing tile %s", at, dir, id)
+					continue
+				}
+				if back := next.Nav[opposite[d]]; back != t.Ident {
+					report("%s: %s to %s is one-way", at, dir, id)
+				}
+				if door := t.Doors.Door(dir); door != nil {
+					if door != next.Doors.Door(opposite[d]) {
+						report("%s: %s door not shared with %s", at, dir, id)
+					}
+				}
+			}
+		}
+
+		if len(a.Tiles) > 0 {
+			reached := a.reachable(a.Tiles[0].Ident)
+			for _, t := range a.Tiles {
+				if !reached[t.Ident] {
+					report("%s/%s: unreachable from %s", a.Ident, t.Ident, a.Tiles[0].Ident)
+				}
+			}
+		}
+	}
+
+	for _, item := range g.Items.All() {
+		if _, found := g.Catalog.Lookup(item.Name); !found {
+			report("item %s: not in catalog", item.Name)
+		}
+		if item.Location.Area == "" && item.Location.Tile == "" {
+			continue // not placed
+		}
+		if _, _, err := g.Place(item.Location); err != nil {
+			report("item %s: %v", item.Name, err)
+		}
+	}
+	for _, s := range g.Spawns {
+		if _, found := g.Catalog.Lookup(s.Name); !found {
+			report("spawn %s: not in catalog", s.Name)
+		}
+		if _, _, err := g.Place(s.Location); err != nil {
+			report("spawn %s: %v", s.Name, err)
+		}
+	}
+	for _, npc := range g.NPCs {
+		if _, _, err := g.Place(npc.Location); err != nil {
+			report("npc %s: %v", npc.Name, err)
+		}
+		checkScripts(report, fmt.Sprintf("npc %s", npc.Name), npc.Scripts)
+	}
+	for _, def := range g.Catalog {
+		checkScripts(report, fmt.Sprintf("item %s", def.Name), def.Scripts)
+	}
+	return problems
+}
+
+func checkScripts(report func(string, ...interface{}), at string, scripts Scripts) {
+	for trigger, src := range scripts {
+		if _, err := src.Compile(); err != nil {
+			report("%s: %s script %v", at, trigger, err)
+		}
+	}
+}
+
+// reachable returns all tiles that can be reached from the given
+// one, regardless of doors.
+func (a *Area) reachable(from Ident) map[Ident]bool {
+	reached := map[Ident]bool{from: true}
+	queue := []Ident{from}
+	for len(queue) > 0 {
+		t, err := a.Tile(queue[0])
+		queue = queue[1:]
+		if err != nil {
+			continue
+		}
+		for _, id := range t.Nav {
+			if id != "" && !reached[id] {
+				reached[id] = true
+				queue = append(queue, id)
+			}
+		}
+	}
+	return reached
+}
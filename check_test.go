@@ -0,0 +1,53 @@
+package cible
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCheckWorld(t *testing.T) {
+	if problems := CheckWorld(NewGame); len(problems) > 0 {
+		t.Error(problems)
+	}
+
+	problems := CheckWorld(func() *Game {
+		g := NewGame()
+		a := &Area{Ident: "a2", Title: "Broken"}
+		t1 := &Tile{Short: "one", Long: "first"}
+		t2 := &Tile{Short: "two"}
+		t3 := &Tile{Short: "three", Long: "island"}
+		a.AddTile(t1, t2, t3)
+		t1.Nav[N] = t2.Ident // one-way
+		t2.Nav[E] = "t9"     // dangling
+		t3.Ident = "t2"      // duplicate
+		g.Areas = append(g.Areas, a)
+		g.Items.Add(&Item{
+			Name:     "ghost",
+			Location: Location{Area: "a2", Tile: "t7"},
+		})
+		return g
+	})
+	all := fmt.Sprint(problems)
+	for _, exp := range []string{
+		"one-way",
+		"missing tile t9",
+		"duplicate tile",
+		"empty long",
+		"item ghost",
+	} {
+		if !strings.Contains(all, exp) {
+			t.Errorf("missing %q in\n%s", exp, all)
+		}
+	}
+
+	problems = CheckWorld(func() *Game {
+		t1, t2, t3 := &Tile{Ident: "t1"}, &Tile{Ident: "t2"}, &Tile{Ident: "t3"}
+		t1.Link(t2, N)
+		t1.Link(t3, N)
+		return NewGame()
+	})
+	if len(problems) != 1 {
+		t.Error("panic not reported", problems)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,6 +29,13 @@ type Client struct {
 
 	Out chan Message
 	In  chan Message
+
+	// Server is set when connected and tells which events the
+	// server supports.
+	Server *Hello
+
+	// Compress large messages if the server supports it
+	Compress bool
 }
 
 func (me *Client) Connect(ctx context.Context) error {
@@ -40,6 +46,13 @@ func (me *Client) Connect(ctx context.Context) error {
 	me.Conn = conn
 	me.Log("connected to", me.Host)
 	tr := NewTransceiver(conn, &GobProtocol{})
+	hello, err := me.handshake(tr)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	me.Server = hello
+	negotiate(tr, me.Compress, hello)
 
 	// transmit outgoing messages
 	go func() {
@@ -72,6 +85,21 @@ func (me *Client) Connect(ctx context.Context) error {
 	return nil
 }
 
+// handshake sends hello and waits for the servers hello, or error if
+// versions are incompatible.
+func (me *Client) handshake(tr *Transceiver) (*Hello, error) {
+	me.Conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	defer me.Conn.SetReadDeadline(time.Time{})
+	if err := tr.Transmit(NewHelloMessage(compression(me.Compress))); err != nil {
+		return nil, err
+	}
+	var m Message
+	if err := tr.Receive(&m); err != nil {
+		return nil, err
+	}
+	return readHello(&m)
+}
+
 // ----------------------------------------
 
 func NewMessage[T any](v *T) Message {
@@ -79,8 +107,9 @@ func NewMessage[T any](v *T) Message {
 	gob.NewEncoder(&buf).Encode(*v)
 	return Message{
 		Id:        uuid.NewString(),
-		EventName: fmt.Sprintf("%T", *v),
+		EventName: eventName(v),
 		Body:      buf.Bytes(),
+		packed:    &packed{},
 	}
 }
 
@@ -90,25 +119,37 @@ type Message struct {
 	Id        string
 	EventName string
 	Body      []byte
+
+	Compressed bool // body, see Transceiver.CompressAbove
+
+	packed *packed // nil if not created with NewMessage
 }
 
 func (m *Message) String() string {
 	id, _ := uuid.Parse(m.Id)
-	name := m.EventName
-	if strings.HasPrefix(name, "cible.") {
-		name = name[6:]
-	}
 	return fmt.Sprintf(
-		"%s[%v] %v bytes", name, id.ID(), m.Size(),
+		"%s[%v] %v bytes", m.EventName, id.ID(), m.Size(),
 	)
 }
 
+// Size returns the number of bytes in the message, excluding
+// encoding overhead.
 func (m *Message) Size() int {
-	var buf bytes.Buffer
-	gob.NewEncoder(&buf).Encode(*m)
-	return buf.Len()
+	return len(m.Id) + len(m.EventName) + len(m.Body)
+}
+
+// NewErrorMessage returns a message telling the other side the
+// message with the given id failed.
+func NewErrorMessage(id string, err error) Message {
+	return Message{
+		Id:        id,
+		EventName: "error",
+		Body:      []byte(err.Error()),
+	}
 }
 
+// CheckError returns the error in messages created with
+// NewErrorMessage.
 func (m *Message) CheckError() error {
 	if m.EventName == "error" {
 		return fmt.Errorf("%s", string(m.Body))
@@ -21,13 +21,73 @@ func main() {
 		bind      = cli.Option("-b, --bind").String("192.168.1.72:8089")
 		debugFlag = cli.Flag("-d, --debug")
 		srv       = cli.Flag("-s, --server")
+		sharded   = cli.Flag("--sharded")
+		compress  = cli.Option("--compress",
+			"compress messages above 512 bytes, negotiated on connect",
+			"so only used if both client and server set it",
+		).Bool()
+		charDir = cli.Option("-c, --characters",
+			"directory where server saves characters",
+		).String("characters")
+		news = cli.Option("-n, --news",
+			"file or directory with headlines for news boards",
+		).String("")
+		journal = cli.Option("-j, --journal",
+			"file where server appends accepted events",
+		).String("")
+		replay = cli.Option("-r, --replay",
+			"journal to reconstruct the game from before serving",
+		).String("")
 	)
 	cli.Parse()
+
+	if cli.Argn(0) == "world" {
+		switch cli.Argn(1) {
+		case "check":
+			os.Exit(checkWorld())
+		default:
+			fmt.Println("usage: cible world check")
+			os.Exit(1)
+		}
+	}
+
 	if srv {
 		defer configureLog(debugFlag)() // configure and defer cleanup
 
 		g := NewGame()
 		g.Logger = mlog
+		store, err := NewDirStore(charDir)
+		if err != nil {
+			mlog.Log(err)
+			os.Exit(1)
+		}
+		g.Store = store
+		g.Sharded = sharded
+		if news != "" {
+			for _, a := range g.Areas {
+				for _, t := range a.Tiles {
+					if t.NewsBoard != nil {
+						t.NewsBoard.SetSource(news)
+					}
+				}
+			}
+		}
+
+		if replay != "" {
+			if err := replayJournal(g, replay); err != nil {
+				mlog.Log(err)
+				os.Exit(1)
+			}
+		}
+		if journal != "" {
+			w, err := os.OpenFile(journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				mlog.Log(err)
+				os.Exit(1)
+			}
+			defer w.Close()
+			g.Journal = NewJournal(w)
+		}
 
 		ctx, cancel := context.WithCancel(context.Background())
 		go func() {
@@ -37,7 +97,7 @@ func main() {
 			cancel() // when game stops, stop the server
 		}()
 
-		srv := &Server{Logger: mlog, Bind: bind}
+		srv := &Server{Logger: mlog, Bind: bind, Compress: compress}
 		if err := srv.Run(ctx, g); err != nil {
 			srv.Log(err)
 			os.Exit(1)
@@ -46,6 +106,7 @@ func main() {
 	}
 	c := NewClient()
 	c.Host = bind
+	c.Compress = compress
 
 	ctx := context.Background()
 	if err := c.Connect(ctx); err != nil {
@@ -73,3 +134,30 @@ func configureLog(debugFlag bool) (cleanup func()) {
 	}
 	return func() { _ = w.Close() }
 }
+
+// replayJournal reconstructs the game from the named journal.
+func replayJournal(g *Game, filename string) error {
+	r, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	n, err := g.Replay(r)
+	g.Logf("replayed %v events from %s", n, filename)
+	return err
+}
+
+// checkWorld prints problems found in the world and returns the exit
+// code.
+func checkWorld() int {
+	problems := CheckWorld(NewGame)
+	for _, err := range problems {
+		fmt.Println(err)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("%v problems found\n", len(problems))
+		return 1
+	}
+	fmt.Println("ok")
+	return 0
+}
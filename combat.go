@@ -0,0 +1,179 @@
+package cible
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// attack starts a fight between the character and the named target
+// on the same tile. The target fights back. Returns a note for the
+// attacker.
+func (g *Game) attack(c *Character, target Name) string {
+	var defender *Character
+	for _, other := range g.Characters.At(c.Location) {
+		if other.Ident != c.Ident && lower(other.Name) == lower(target) {
+			defender = other
+			break
+		}
+	}
+	if defender == nil {
+		return fmt.Sprintf("there is no %s here", target)
+	}
+	if g.fights[c.Ident] == defender.Ident {
+		return fmt.Sprintf("you are already fighting %s", defender.Name)
+	}
+	g.startFight(c, defender)
+	return fmt.Sprintf("you attack %s", defender.Name)
+}
+
+func (g *Game) startFight(attacker, defender *Character) {
+	g.fights[attacker.Ident] = defender.Ident
+	if _, found := g.fights[defender.Ident]; !found {
+		g.fights[defender.Ident] = attacker.Ident
+	}
+}
+
+// fightRound resolves one round for each ongoing fight. Fights end
+// when one leaves the tile or is defeated.
+func (g *Game) fightRound(now time.Time) {
+	// hostile npcs attack anyone near
+	for id, npc := range g.npcs {
+		if !npc.Hostile {
+			continue
+		}
+		if _, fighting := g.fights[id]; fighting {
+			continue
+		}
+		c, err := g.Character(id)
+		if err != nil {
+			continue
+		}
+		for _, other := range g.Characters.At(c.Location) {
+			if !other.IsBot {
+				g.startFight(c, other)
+				break
+			}
+		}
+	}
+
+	attackers := make([]Ident, 0, len(g.fights))
+	for id := range g.fights {
+		attackers = append(attackers, id)
+	}
+	sort.Slice(attackers, func(i, j int) bool {
+		return attackers[i] < attackers[j]
+	})
+	for _, id := range attackers {
+		target, found := g.fights[id]
+		if !found {
+			continue // ended this round
+		}
+		a, err := g.Character(id)
+		if err != nil {
+			delete(g.fights, id)
+			continue
+		}
+		d, err := g.Character(target)
+		if err != nil || !a.Location.Equal(d.Location) {
+			delete(g.fights, id)
+			continue
+		}
+		if a.Energy < attackEnergy {
+			continue // too tired, catching breath
+		}
+		a.Energy -= attackEnergy
+		dmg := damage(g.Stats(a), g.Stats(d), rand.Intn(4))
+		d.Health -= dmg
+		e := &EventCombat{
+			Attacker: a.Name,
+			Defender: d.Name,
+			Damage:   dmg,
+			Health:   d.Health,
+			Defeated: d.Health <= 0,
+		}
+		m := NewMessage(e)
+		a.Transmit(m)
+		a.TransmitOthers(g, m)
+		if e.Defeated {
+			g.defeat(a, d, now)
+		}
+	}
+}
+
+// damage returns the damage an attacker does to a defender, at least
+// one.
+func damage(a, d Stats, luck int) int {
+	dmg := a.Strength + luck - d.Defense
+	if dmg < 1 {
+		return 1
+	}
+	return dmg
+}
+
+// defeat ends all fights of the loser. NPCs drop their loot and are
+// respawned later, players are sent back to start.
+func (g *Game) defeat(winner, loser *Character, now time.Time) {
+	for id, target := range g.fights {
+		if id == loser.Ident || target == loser.Ident {
+			delete(g.fights, id)
+		}
+	}
+	if npc, found := g.npcs[loser.Ident]; found {
+		delete(g.npcs, loser.Ident)
+		g.Characters.Remove(loser.Ident)
+		for _, item := range npc.Loot {
+			loot := *item
+			loot.Location = loser.Location
+			g.Items.Add(&loot)
+		}
+		if npc.RespawnAfter > 0 {
+			g.respawns = append(g.respawns, respawn{npc, now.Add(npc.RespawnAfter)})
+		}
+		if !winner.IsBot {
+			g.gain(winner, defeatExperience*npc.Level)
+		}
+		return
+	}
+
+	// player
+	g.stopWalk(loser.Ident, nil)
+	m := NewMessage(&EventGoAway{Name: loser.Name})
+	loser.TransmitOthers(g, m)
+	g.Characters.Move(loser, startLocation)
+	loser.Health = loser.MaxHealth
+	a, t, _ := g.Place(loser.Location)
+	loser.Transmit(NewMessage(&EventMove{
+		Location: loser.Location,
+		Title:    a.Title,
+		Tile:     g.view(loser, t),
+		Body:     []byte(t.Short + "..."),
+	}))
+	loser.TransmitOthers(g, NewMessage(&EventApproach{Name: loser.Name}))
+}
+
+// placeNPC adds a character for the NPC to the game.
+func (g *Game) placeNPC(npc *NPC) {
+	c := npc.newCharacter()
+	g.Characters.Add(c)
+	g.npcs[c.Ident] = npc
+}
+
+// respawnNPCs places defeated NPCs that are due.
+func (g *Game) respawnNPCs(now time.Time) {
+	waiting := g.respawns[:0]
+	for _, r := range g.respawns {
+		if now.Before(r.at) {
+			waiting = append(waiting, r)
+			continue
+		}
+		g.placeNPC(r.NPC)
+	}
+	g.respawns = waiting
+}
+
+const (
+	attackEnergy     = 2
+	defeatExperience = 20 // times npc level
+)
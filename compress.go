@@ -0,0 +1,84 @@
+package cible
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Flate compression of message bodies, see Hello.Compression
+const Flate = "flate"
+
+// DefaultCompressAbove is the body size in bytes above which
+// messages are compressed, once negotiated.
+const DefaultCompressAbove = 512
+
+// maxBodySize limits decompressed bodies
+const maxBodySize = 1 << 20
+
+// compression returns the compression advertised in hello messages.
+func compression(enabled bool) string {
+	if enabled {
+		return Flate
+	}
+	return ""
+}
+
+// negotiate enables compression on the transceiver if both sides
+// want it.
+func negotiate(tr *Transceiver, enabled bool, other *Hello) {
+	if enabled && other.Compression == Flate {
+		tr.CompressAbove = DefaultCompressAbove
+	}
+}
+
+// packed is the compressed body of a message, shared by all copies
+// of the message so it's compressed once regardless of the number
+// of recipients.
+type packed struct {
+	once sync.Once
+	body []byte
+	err  error
+}
+
+func (me *packed) compress(body []byte) ([]byte, error) {
+	me.once.Do(func() { me.body, me.err = compress(body) })
+	return me.body, me.err
+}
+
+func compress(body []byte) ([]byte, error) {
+	w := flateWriters.Get().(*flate.Writer)
+	defer flateWriters.Put(w)
+	var buf bytes.Buffer
+	w.Reset(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(body []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(body))
+	defer r.Close()
+	res, err := io.ReadAll(io.LimitReader(r, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(res) > maxBodySize {
+		return nil, fmt.Errorf("body exceeds %v bytes", maxBodySize)
+	}
+	return res, nil
+}
+
+// writers are expensive to create
+var flateWriters = sync.Pool{
+	New: func() any {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return w
+	},
+}
@@ -0,0 +1,123 @@
+package cible
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Door guards a link between two tiles. The same door is referenced
+// from both tiles so its state is shared by everyone.
+type Door struct {
+	Short // e.g. gate
+
+	Closed bool
+	Locked bool
+
+	// Key is the name of the item needed to lock or unlock the
+	// door. Doors without a key cannot be locked.
+	Key Name
+}
+
+func (me *Door) String() string {
+	switch {
+	case me.Locked:
+		return fmt.Sprintf("%s (locked)", me.Short)
+	case me.Closed:
+		return fmt.Sprintf("%s (closed)", me.Short)
+	}
+	return string(me.Short)
+}
+
+// Passable returns nil if one can walk through the door.
+func (me *Door) Passable() error {
+	switch {
+	case me.Locked:
+		return fmt.Errorf("the %s is locked", me.Short)
+	case me.Closed:
+		return fmt.Errorf("the %s is closed", me.Short)
+	}
+	return nil
+}
+
+func (me *Door) Open() error {
+	switch {
+	case me.Locked:
+		return fmt.Errorf("the %s is locked", me.Short)
+	case !me.Closed:
+		return fmt.Errorf("the %s is already open", me.Short)
+	}
+	me.Closed = false
+	return nil
+}
+
+func (me *Door) Close() error {
+	if me.Closed {
+		return fmt.Errorf("the %s is already closed", me.Short)
+	}
+	me.Closed = true
+	return nil
+}
+
+// Unlock unlocks the door if the given items contain the key.
+func (me *Door) Unlock(items Items) error {
+	if !me.Locked {
+		return fmt.Errorf("the %s is not locked", me.Short)
+	}
+	if _, err := items.FindByName(me.Key); err != nil {
+		return fmt.Errorf("you need a %s to unlock the %s", me.Key, me.Short)
+	}
+	me.Locked = false
+	return nil
+}
+
+// Lock locks a closed door if the given items contain the key.
+func (me *Door) Lock(items Items) error {
+	switch {
+	case me.Key == "":
+		return fmt.Errorf("the %s has no lock", me.Short)
+	case me.Locked:
+		return fmt.Errorf("the %s is already locked", me.Short)
+	case !me.Closed:
+		return fmt.Errorf("close the %s first", me.Short)
+	}
+	if _, err := items.FindByName(me.Key); err != nil {
+		return fmt.Errorf("you need a %s to lock the %s", me.Key, me.Short)
+	}
+	me.Locked = true
+	return nil
+}
+
+// Use applies the action on the door using the given items as
+// possible keys.
+func (me *Door) Use(a DoorAction, items Items) error {
+	switch a {
+	case OpenDoor:
+		return me.Open()
+	case CloseDoor:
+		return me.Close()
+	case LockDoor:
+		return me.Lock(items)
+	case UnlockDoor:
+		return me.Unlock(items)
+	}
+	return fmt.Errorf("cannot %s a door", a)
+}
+
+type DoorAction string
+
+const (
+	OpenDoor   DoorAction = "open"
+	CloseDoor  DoorAction = "close"
+	LockDoor   DoorAction = "lock"
+	UnlockDoor DoorAction = "unlock"
+)
+
+// Doors of a tile by direction
+type Doors map[Direction]*Door
+
+// Door returns the door in the given direction, nil if none.
+func (me Doors) Door(d Direction) *Door {
+	return me[d]
+}
+
+var ErrNoDoor = errors.New("there is no door in that direction")
@@ -0,0 +1,45 @@
+package cible
+
+import "fmt"
+
+// Effect returns the events triggered when the character uses the
+// item. The events are applied to the game like any other.
+type Effect func(g *Game, c *Character, item *Item) ([]Event, error)
+
+// Effects by name, referenced from ItemDef.Effect
+type Effects map[Name]Effect
+
+func DefaultEffects() Effects {
+	return Effects{
+		"tune":   tuneEffect,
+		"unlock": unlockEffect,
+	}
+}
+
+// tuneEffect toggles listening to the global channel
+func tuneEffect(g *Game, c *Character, item *Item) ([]Event, error) {
+	return []Event{&EventTune{Ident: c.Ident}}, nil
+}
+
+// unlockEffect unlocks all doors on the current tile that the item
+// is key to.
+func unlockEffect(g *Game, c *Character, item *Item) ([]Event, error) {
+	_, t, err := g.Place(c.Location)
+	if err != nil {
+		return nil, err
+	}
+	var res []Event
+	for d, door := range t.Doors {
+		if door.Locked && lower(door.Key) == lower(item.Name) {
+			res = append(res, &EventDoor{
+				DoorAction: UnlockDoor,
+				Direction:  d,
+				Ident:      c.Ident,
+			})
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("nothing to unlock here")
+	}
+	return res, nil
+}
@@ -4,40 +4,253 @@ import (
 	"encoding/gob"
 	"fmt"
 	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 )
 
+// Event names are part of the protocol and independent of the Go
+// types, renaming a type is fine, renaming an event breaks older
+// clients.
 func init() {
-	registerEvent(&EventJoinGame{})
-	registerEvent(&EventJoin{})
-	registerEvent(&EventSay{})
-	registerEvent(&EventLeave{})
-	registerEvent(&EventMove{})
-	registerEvent(&EventLook{})
-	registerEvent(&EventDisconnect{})
-	registerEvent(&EventApproach{})
-	registerEvent(&EventGoAway{})
-	registerEvent(&EventPickup{})
-	registerEvent(&EventExamine{})
-	registerEvent(&EventInventoryUpdate{})
+	registerEvent("join-game", &EventJoinGame{})
+	registerEvent("join", &EventJoin{})
+	registerEvent("say", &EventSay{})
+	registerEvent("leave", &EventLeave{})
+	registerEvent("move", &EventMove{})
+	registerEvent("look", &EventLook{})
+	registerEvent("disconnect", &EventDisconnect{})
+	registerEvent("approach", &EventApproach{})
+	registerEvent("go-away", &EventGoAway{})
+	registerEvent("pickup", &EventPickup{})
+	registerEvent("examine", &EventExamine{})
+	registerEvent("inventory-update", &EventInventoryUpdate{})
+	registerEvent("door", &EventDoor{})
+	registerEvent("goto", &EventGoto{})
+	registerEvent("map", &EventMap{})
+	registerEvent("use", &EventUse{})
+	registerEvent("equip", &EventEquip{})
+	registerEvent("unequip", &EventUnequip{})
+	registerEvent("broadcast", &EventBroadcast{})
+	registerEvent("buy", &EventBuy{})
+	registerEvent("sell", &EventSell{})
+	registerEvent("put", &EventPut{})
+	registerEvent("take", &EventTake{})
+	registerEvent("status", &EventStatus{})
+	registerEvent("attack", &EventAttack{})
+	registerEvent("combat", &EventCombat{})
+	registerEvent("quests", &EventQuests{})
+	registerEvent("quest-update", &EventQuestUpdate{})
+	registerEvent("notice", &EventNotice{})
+	registerEvent("read-news", &EventReadNews{})
+	registerEvent("post-news", &EventPostNews{})
 
 	// Do Not register EventStopGame as it would allow a client to
-	// stop the server.
+	// stop the server. Same goes for events triggered by item
+	// effects, e.g. EventTune, and EventTick.
 }
 
 // ----------------------------------------
 
 type EventInventoryUpdate struct {
 	*Inventory
+
+	Note string // e.g. result of a trade
+}
+
+// EventBuy buys one item from a vendor on the same tile
+type EventBuy struct {
+	Item
+
+	// set by server
+	Ident
+
+	// set by game
+	Note string
+}
+
+func (e *EventBuy) Validate() error {
+	if e.Item.Name == "" {
+		return fmt.Errorf("missing item")
+	}
+	return nil
+}
+
+// EventSell sells one item to a vendor on the same tile
+type EventSell struct {
+	Item
+
+	// set by server
+	Ident
+
+	// set by game
+	Note string
+}
+
+func (e *EventSell) Validate() error {
+	if e.Item.Name == "" {
+		return fmt.Errorf("missing item")
+	}
+	return nil
+}
+
+// EventPut puts an item from the inventory into a container
+type EventPut struct {
+	Item
+	Container Name
+
+	// set by server
+	Ident
+
+	// set by game
+	Note string
+}
+
+func (e *EventPut) Validate() error {
+	if e.Item.Name == "" || e.Container == "" {
+		return fmt.Errorf("missing item or container")
+	}
+	return nil
+}
+
+// EventTake takes an item from a container into the inventory
+type EventTake struct {
+	Item
+	Container Name
+
+	// set by server
+	Ident
+
+	// set by game
+	Note string
+}
+
+func (e *EventTake) Validate() error {
+	if e.Item.Name == "" || e.Container == "" {
+		return fmt.Errorf("missing item or container")
+	}
+	return nil
+}
+
+// EventStatus requests the stats of your character
+type EventStatus struct {
+	// set by server
+	Ident
+
+	// set by game
+	Name
+	Stats
+	Note string // e.g. reached new level
+}
+
+func (*EventStatus) Validate() error { return nil }
+
+// EventAttack starts a fight with a character on the same tile,
+// rounds are resolved by the game until one is defeated or leaves.
+type EventAttack struct {
+	Target Name
+
+	// set by server
+	Ident
+
+	// set by game
+	Note string
+}
+
+func (e *EventAttack) Validate() error {
+	if e.Target == "" {
+		return fmt.Errorf("missing target")
+	}
+	return nil
+}
+
+// EventCombat is the outcome of one round, sent to everyone on the
+// tile.
+type EventCombat struct {
+	Attacker Name
+	Defender Name
+	Damage   int
+	Health   int // left for defender
+	Defeated bool
+}
+
+// EventQuests requests your quests and their progress
+type EventQuests struct {
+	// set by server
+	Ident
+
+	// set by game
+	Quests []QuestStatus
+}
+
+func (*EventQuests) Validate() error { return nil }
+
+// EventQuestUpdate is sent when an objective or quest is done
+type EventQuestUpdate struct {
+	Title
+	Note string
+}
+
+// EventNotice is a message from the world, e.g. sent by scripts.
+type EventNotice struct {
+	Text string
+}
+
+// EventReadNews requests headlines from the news board on your
+// tile. Also sent by the game when fresh news arrive.
+type EventReadNews struct {
+	// set by server
+	Ident
+
+	// set by game
+	Headlines []Headline
+	Note      string
+}
+
+func (*EventReadNews) Validate() error { return nil }
+
+// EventPostNews posts a headline on the news board on your tile
+type EventPostNews struct {
+	Text string
+
+	// set by server
+	Ident
+
+	// set by game
+	Note string
+}
+
+func (e *EventPostNews) Validate() error {
+	if e.Text == "" {
+		return fmt.Errorf("missing text")
+	}
+	return nil
+}
+
+// EventTick is triggered by the game at regular intervals for time
+// based changes.
+type EventTick struct {
+	Now time.Time
 }
 
 type EventExamine struct {
 	Ident
 	Item
 
+	// set by game
+	Def *ItemDef
 	Interactions
 	Note string
 }
 
+func (e *EventExamine) Validate() error {
+	if e.Item.Name == "" {
+		return fmt.Errorf("missing item")
+	}
+	return nil
+}
+
 type EventPickup struct {
 	Ident
 	Item
@@ -45,6 +258,13 @@ type EventPickup struct {
 	ItemFound
 }
 
+func (e *EventPickup) Validate() error {
+	if e.Item.Name == "" {
+		return fmt.Errorf("missing item")
+	}
+	return nil
+}
+
 type EventGoAway struct {
 	Name
 }
@@ -65,6 +285,15 @@ type EventJoinGame struct {
 	Title // of the area
 }
 
+func (e *EventJoinGame) Validate() error {
+	if e.Player.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	return nil
+}
+
+func (e *EventJoinGame) setTransmitter(tr Transmitter) { e.tr = tr }
+
 type EventJoin struct {
 	// set by game
 	Ident
@@ -81,6 +310,8 @@ type EventSay struct {
 	Name
 }
 
+func (*EventSay) Validate() error { return nil }
+
 type EventLeave struct {
 	// set by server
 	Ident
@@ -89,6 +320,8 @@ type EventLeave struct {
 	Name
 }
 
+func (*EventLeave) Validate() error { return nil }
+
 type EventDisconnect struct {
 	// set by server
 	Ident
@@ -106,12 +339,152 @@ type EventMove struct {
 	Title // of the area
 	*Tile
 	Body []byte
+
+	Note string // why the move was refused
+
+	walk *walk // set if part of a goto
+}
+
+func (e *EventMove) Validate() error {
+	if e.Direction < N || e.Direction > NW {
+		return fmt.Errorf("invalid direction %v", int(e.Direction))
+	}
+	return nil
 }
 
 func (me *EventMove) String() string {
 	return fmt.Sprintf("%s => %s", me.Direction, me.Location)
 }
 
+// EventUse uses an item in the inventory, triggering its effect.
+type EventUse struct {
+	Item
+
+	// set by server
+	Ident
+
+	// set by game
+	Used bool
+	Note string
+}
+
+func (e *EventUse) Validate() error {
+	if e.Item.Name == "" {
+		return fmt.Errorf("missing item")
+	}
+	return nil
+}
+
+// EventTune toggles listening to the global channel, triggered by
+// using the communicator.
+type EventTune struct {
+	Ident
+}
+
+// EventBroadcast is spoken on the global channel, heard by all who
+// are tuned in.
+type EventBroadcast struct {
+	Text string
+
+	// set by server
+	Ident
+
+	// set by game
+	Name
+}
+
+func (e *EventBroadcast) Validate() error {
+	if e.Text == "" {
+		return fmt.Errorf("missing text")
+	}
+	return nil
+}
+
+type EventEquip struct {
+	Item
+
+	// set by server
+	Ident
+
+	// set by game
+	Note string
+}
+
+func (e *EventEquip) Validate() error {
+	if e.Item.Name == "" {
+		return fmt.Errorf("missing item")
+	}
+	return nil
+}
+
+type EventUnequip struct {
+	Item
+
+	// set by server
+	Ident
+
+	// set by game
+	Note string
+}
+
+func (e *EventUnequip) Validate() error {
+	if e.Item.Name == "" {
+		return fmt.Errorf("missing item")
+	}
+	return nil
+}
+
+// EventMap requests a map of the area the character is in.
+type EventMap struct {
+	// set by server
+	Ident
+
+	// set by game
+	*AreaMap
+}
+
+func (*EventMap) Validate() error { return nil }
+
+// EventGoto walks the character to the destination tile, one move
+// at the time.
+type EventGoto struct {
+	Destination string // tile short name or ident
+
+	// set by server
+	Ident
+
+	// set by game
+	Path []Direction
+	Note string
+}
+
+func (e *EventGoto) Validate() error {
+	if e.Destination == "" {
+		return fmt.Errorf("missing destination")
+	}
+	return nil
+}
+
+// EventDoor opens, closes, locks or unlocks the door in the given
+// direction.
+type EventDoor struct {
+	DoorAction
+	Direction
+
+	// set by server
+	Ident
+
+	// set by game
+	Note string
+}
+
+func (e *EventDoor) Validate() error {
+	if e.Direction < N || e.Direction > NW {
+		return fmt.Errorf("invalid direction %v", int(e.Direction))
+	}
+	return nil
+}
+
 type EventLook struct {
 	// set by server
 	Ident // character who is looking
@@ -121,13 +494,16 @@ type EventLook struct {
 	Loose Items
 }
 
+func (*EventLook) Validate() error { return nil }
+
 type EventStopGame struct{}
 
 // ----------------------------------------
 
-// NewEvent returns a new instance of the named event. Returns false
-// if the event has not been registered.
-func NewEvent(name string) (Event, bool) {
+// NewEvent returns a new instance of the named event or
+// notification, e.g. EventJoin. Returns false if it has not been
+// registered.
+func NewEvent(name string) (any, bool) {
 	if fn, found := eventConstructors[name]; !found {
 		log.Println(name, "NOT REGISTERED")
 		return nil, false
@@ -136,15 +512,64 @@ func NewEvent(name string) (Event, bool) {
 	}
 }
 
-type Event interface{}
+// Event affects the game, see Game.AffectGame. Only events
+// implementing ClientEvent may be sent by players, all others are
+// created by the server, e.g. EventTick.
+type Event interface {
+	AffectGame(*Game) error
+}
 
-// register pointer to events
-func registerEvent[T Event](t *T) {
+// ClientEvent is an event players may send.
+type ClientEvent interface {
+	Event
+
+	// Validate returns an error if the event is malformed, before
+	// it reaches the game.
+	Validate() error
+}
+
+// Signed events carry the character who sent them, set by the
+// server so players cannot act as someone else.
+type Signed interface {
+	Sign(Ident)
+}
+
+// EventNames returns the sorted names of all registered events and
+// notifications.
+func EventNames() []string {
+	names := make([]string, 0, len(eventConstructors))
+	for name := range eventConstructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// eventName returns the registered name of the event, falls back
+// to the Go type name for unregistered events.
+func eventName(v any) string {
+	if name, found := eventNames[reflect.TypeOf(v)]; found {
+		return name
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", v), "*")
+}
+
+// register pointer to events and notifications by name
+func registerEvent[T any](name string, t *T) {
 	gob.Register(*t)
-	eventConstructors[fmt.Sprintf("%T", *t)] = func() Event {
+	eventConstructors[name] = func() any {
 		var x T
 		return &x
 	}
+	eventNames[reflect.TypeOf(t)] = name
+	typeNames[strings.TrimPrefix(fmt.Sprintf("%T", t), "*")] = name
 }
 
-var eventConstructors = make(map[string]func() Event)
+var (
+	eventConstructors = make(map[string]func() any)
+	eventNames        = make(map[reflect.Type]string)
+
+	// typeNames maps Go type names, e.g. cible.EventMove, used as
+	// event names before protocol version 1, to registered names
+	typeNames = make(map[string]string)
+)
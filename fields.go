@@ -15,3 +15,5 @@ type ItemFound bool
 type Ident string
 
 func (me *Ident) SetIdent(v string) { *me = Ident(v) }
+func (me *Ident) ident() Ident      { return *me }
+func (me *Ident) Sign(v Ident)      { *me = v }
@@ -4,41 +4,136 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gregoryv/logger"
 )
 
 func NewGame() *Game {
-	return &Game{
-		World:      NewWorld(),
-		Characters: NewCharactersMap(),
-		Items: Items{
-			{
-				Name:     "ball",
-				Count:    1,
-				Location: Location{Area: "a1", Tile: "t9"},
-			},
+	g := &Game{
+		World:          NewWorld(),
+		Characters:     NewCharactersMap(),
+		Items:          NewItemsMap(),
+		Effects:        DefaultEffects(),
+		Modifiers:      DefaultModifiers(),
+		AfterHooks:     DefaultAfterHooks(),
+		MaxTasks:       10,
+		OutboxSize:     100,
+		EnqueueTimeout: 100 * time.Millisecond,
+		StepDelay:      300 * time.Millisecond,
+		TickInterval:   time.Second,
+		Logger:         logger.Silent,
+		HealthRegen:    Regen{Every: 5 * time.Second},
+		EnergyRegen:    Regen{Every: time.Second},
+		ScriptLimits: ScriptLimits{
+			MaxOps:  1000,
+			Timeout: 10 * time.Millisecond,
 		},
-		MaxTasks: 10,
-		Logger:   logger.Silent,
+
+		fights:  make(map[Ident]Ident),
+		walks:   make(map[Ident]*walk),
+		npcs:    make(map[Ident]*NPC),
+		scripts: make(map[Script]compiled),
+	}
+	for _, s := range g.Spawns {
+		g.spawn(s, s.Count)
 	}
+	for _, npc := range g.NPCs {
+		g.placeNPC(npc)
+	}
+	return g
 }
 
 type Game struct {
 	World
 	Characters
-	Items
+	Items *ItemsMap
+	Effects
+	Modifiers []Modifier
+
+	// Middlewares wrap all events affecting the game, first one is
+	// outermost. BeforeHooks may veto events and AfterHooks observe
+	// the result, e.g. quest progress. Set them before Run. When
+	// Sharded they are called from several goroutines at once and
+	// must be safe for concurrent use.
+	Middlewares []Middleware
+	BeforeHooks []BeforeHook
+	AfterHooks  []AfterHook
+
+	// Store is used to load and save characters, nil means new
+	// characters are created each time a player joins.
+	Store CharacterStore
 
 	MaxTasks     int
 	LogAllEvents bool
 
-	ch chan *Task
+	// OutboxSize is the number of messages buffered per player,
+	// players not keeping up are disconnected
+	OutboxSize int
+
+	// EnqueueTimeout is how long Enqueue waits for room in a full
+	// queue, zero fails fast
+	EnqueueTimeout time.Duration
+
+	// StepDelay is the pause between moves when walking
+	StepDelay time.Duration
+
+	// TickInterval is how often time based changes, e.g. restocking
+	// vendors, are made
+	TickInterval time.Duration
+
+	// HealthRegen and EnergyRegen is how fast characters recover
+	HealthRegen Regen
+	EnergyRegen Regen
+
+	// ScriptLimits stops runaway scripts
+	ScriptLimits ScriptLimits
+
+	// Sharded runs one event loop per area, see startShards
+	Sharded bool
+
+	// Journal records accepted events, nil means no journal
+	Journal *Journal
+
+	queueOnce sync.Once
+	queueMu   sync.RWMutex // held while enqueuing
+	ch        chan *Task
+	stopped   chan struct{}
+
+	replaying bool
+
+	now time.Time // of last tick
+
+	fights   map[Ident]Ident // attacker -> defender
+	walksMu  sync.Mutex
+	walks    map[Ident]*walk // character -> ongoing goto
+	npcs     map[Ident]*NPC  // character -> definition
+	respawns []respawn
+
+	scriptsMu sync.Mutex
+	scripts   map[Script]compiled // source -> compiled once
+
+	shards        map[Ident]chan *Task // area -> loop
+	shardsRunning sync.WaitGroup
+
 	logger.Logger
 }
 
 func (g *Game) Run(ctx context.Context) error {
 	g.Log("start game")
-	g.ch = make(chan *Task, g.MaxTasks)
+	queue := g.queue()
+	defer g.stop()
+	ticker := time.NewTicker(g.TickInterval)
+	defer ticker.Stop()
+	g.indexAreas()
+	go g.loadNews(ctx, g.newsBoards())
+	g.AffectGame(&EventTick{Now: time.Now()}) // start the clocks
+	if g.Sharded {
+		g.startShards()
+		defer g.stopShards()
+	}
 
 eventLoop:
 	for {
@@ -46,10 +141,20 @@ eventLoop:
 		case <-ctx.Done(): // ie. interrupted from the outside
 			break eventLoop
 
-		case task := <-g.ch: // blocks
+		case now := <-ticker.C:
+			g.barrier()
+			if err := g.AffectGame(&EventTick{Now: now}); err != nil {
+				g.Log(err)
+			}
+
+		case task := <-queue: // blocks
 			if g.LogAllEvents {
 				g.Log(task.String())
 			}
+			if g.route(task) {
+				continue
+			}
+			g.barrier()
 			// One event affects the game
 			err := g.AffectGame(task.Event)
 			if err != nil {
@@ -68,170 +173,1118 @@ eventLoop:
 			task.setErr(err)
 		}
 	}
-	close(g.ch)
 	g.Log("game stopped")
 	return nil
 }
 
-func (g *Game) AffectGame(e interface{}) error {
-	switch e := e.(type) {
+// AffectGame applies the event to the game, through middlewares and
+// hooks. Accepted events are journaled.
+func (g *Game) AffectGame(e Event) error {
+	return g.handle(e, true)
+}
 
-	case *EventSay:
-		c, err := g.Characters.Character(e.Ident)
-		if err != nil {
-			return err
-		}
-		e.Name = c.Name
-		go c.TransmitOthers(g, NewMessage(e))
-
-	case *EventJoin:
-
-	case *EventJoinGame:
-		c := &Character{
-			Name: e.Player.Name,
-			Location: Location{
-				Area: "a1", Tile: "t1",
-			},
-			Inventory: *NewInventory(),
-			tr:        e.tr,
-		}
-		g.Characters.Add(c)
-		g.Logf("%s joined game as %s", c.Name, c.Ident)
-		e.Character = c
-		a, _, _ := g.Place(c.Location)
-		e.Title = a.Title
-
-		// notify others of the new character
-		go c.TransmitOthers(g,
-			NewMessage(&EventJoin{
-				Ident: c.Ident,
-				Name:  c.Name,
-			}),
-		)
-		return c.Transmit(NewMessage(e)) // back to player
-
-	case *EventLeave:
-		c, err := g.Characters.Character(e.Ident)
-		if err != nil {
-			return err
+// handle passes the event through middlewares and hooks. Events
+// that are part of another, e.g. item effects, are not journaled.
+func (g *Game) handle(e Event, journal bool) error {
+	h := EventHandler(func(g *Game, e Event) error {
+		return handleEvent(g, e, journal)
+	})
+	for i := len(g.Middlewares) - 1; i >= 0; i-- {
+		h = g.Middlewares[i](h)
+	}
+	return h(g, e)
+}
+
+func (e *EventSay) AffectGame(g *Game) error {
+	c, err := g.Characters.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	e.Name = c.Name
+	c.TransmitOthers(g, NewMessage(e))
+	if _, t, err := g.Place(c.Location); err == nil {
+		g.runScript(t.Scripts[OnSay], c, e.Text)
+	}
+	for _, other := range g.Characters.At(c.Location) {
+		npc, found := g.npcs[other.Ident]
+		if !found {
+			continue
 		}
-		e.Name = c.Name
-		g.Characters.Remove(c.Ident)
-		g.Logf("%s left, %v remaining", c.Name, g.Characters.Len())
-		go c.TransmitOthers(g, NewMessage(e))
-
-	case *EventMove:
-		g.Logf("%s move %s", e.Ident, e.Direction)
-		c, err := g.Character(e.Ident)
-		if err != nil {
-			return err
+		if npc.Says != "" {
+			c.Transmit(NewMessage(&EventSay{
+				Name: npc.Name,
+				Text: npc.Says,
+			}))
 		}
+		g.runScript(npc.Scripts[OnSay], c, e.Text)
+	}
+	return nil
+}
 
-		_, t, err := g.Place(c.Location)
-		if err != nil {
-			return err
-		}
-		next, err := link(t, e.Direction)
-		if err != nil {
-			return err
+func (e *EventJoinGame) AffectGame(g *Game) error {
+	// one session per name, or saving would lose items of the other
+	for _, other := range g.Characters.All() {
+		if other.Name == e.Player.Name {
+			return fmt.Errorf("%w: %s", ErrNameTaken, e.Player.Name)
 		}
-		// must do this Before setting next position
-		c.TransmitOthers(g, NewMessage(&EventGoAway{Name: c.Name}))
+	}
+	c := g.loadCharacter(e.Player.Name)
+	if e.tr != nil {
+		c.out = NewOutbox(e.tr, g.OutboxSize)
+	}
+	c.Visit(c.Location)
+	g.assignQuests(c)
+	g.Characters.Add(c)
+	g.Logf("%s joined game as %s", c.Name, c.Ident)
+	e.Character = c
+	a, _, _ := g.Place(c.Location)
+	e.Title = a.Title
+
+	// notify others of the new character
+	c.TransmitOthers(g,
+		NewMessage(&EventJoin{
+			Ident: c.Ident,
+			Name:  c.Name,
+		}),
+	)
+	return c.Transmit(NewMessage(e)) // back to player
+}
 
-		if next != "" {
-			c.Location.Tile = next
+func (e *EventLeave) AffectGame(g *Game) error {
+	c, err := g.Characters.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	e.Name = c.Name
+	g.saveCharacter(c)
+	g.stopWalk(c.Ident, nil)
+	g.Characters.Remove(c.Ident)
+	c.closeOutbox()
+	g.Logf("%s left, %v remaining", c.Name, g.Characters.Len())
+	c.TransmitOthers(g, NewMessage(e))
+	return nil
+}
+
+func (e *EventMove) AffectGame(g *Game) error {
+	g.Logf("%s move %s", e.Ident, e.Direction)
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	if !g.walking(c.Ident, e.walk) {
+		return errWalkStopped
+	}
+
+	_, t, err := g.Place(c.Location)
+	if err != nil {
+		return err
+	}
+	next, err := link(t, e.Direction)
+	if err != nil {
+		return err
+	}
+	if next == "" {
+		e.Note = "cannot move in that direction"
+	} else if c.Energy < moveEnergy {
+		e.Note = "you are too tired to move, rest a while"
+	} else if door := t.Doors.Door(e.Direction); door != nil {
+		if err := door.Passable(); err != nil {
+			e.Note = err.Error()
 		}
+	}
+	if e.Note != "" {
 		e.Location = c.Location
-		a, t, _ := g.Place(c.Location)
-		e.Tile = t
-		e.Title = a.Title
-		e.Body = []byte(t.Short + "...")
-		go c.Transmit(NewMessage(e))
-		go c.TransmitOthers(g, NewMessage(&EventApproach{Name: c.Name}))
-
-	case *EventLook:
-		c, err := g.Character(e.Ident)
+		c.Transmit(NewMessage(e))
+		return nil
+	}
+	// must do this Before setting next position
+	c.TransmitOthers(g, NewMessage(&EventGoAway{Name: c.Name}))
+
+	g.Characters.Move(c, Location{Area: c.Location.Area, Tile: next})
+	c.Energy -= moveEnergy
+	if c.Visit(c.Location) {
+		g.gain(c, visitExperience)
+	}
+	e.Location = c.Location
+	a, t, _ := g.Place(c.Location)
+	e.Tile = g.view(c, t)
+	e.Title = a.Title
+	e.Body = []byte(t.Short + "...")
+	c.Transmit(NewMessage(e))
+	c.TransmitOthers(g, NewMessage(&EventApproach{Name: c.Name}))
+	g.runScript(t.Scripts[OnEnter], c, "")
+	return nil
+}
+
+func (e *EventMap) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	a, _, err := g.Place(c.Location)
+	if err != nil {
+		return err
+	}
+	e.AreaMap = NewAreaMap(a)
+	c.Transmit(NewMessage(e))
+	return nil
+}
+
+func (e *EventGoto) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	a, _, err := g.Place(c.Location)
+	if err != nil {
+		return err
+	}
+	dest, err := a.FindTile(e.Destination)
+	if err != nil {
+		e.Note = fmt.Sprintf("there is no %s here", e.Destination)
+		c.Transmit(NewMessage(e))
+		return nil
+	}
+	e.Path, err = a.Path(c.Location.Tile, dest.Ident)
+	switch {
+	case err != nil:
+		e.Note = fmt.Sprintf("cannot find a way to %s", dest.Short)
+	case len(e.Path) == 0:
+		e.Note = fmt.Sprintf("you are already in %s", dest.Short)
+	default:
+		e.Note = fmt.Sprintf("walking to %s", dest.Short)
+		if !g.replaying { // journaled moves follow
+			g.startWalk(c.Ident, e.Path)
+		}
+	}
+	c.Transmit(NewMessage(e))
+	return nil
+}
+
+func (e *EventDoor) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	_, t, err := g.Place(c.Location)
+	if err != nil {
+		return err
+	}
+	door := t.Doors.Door(e.Direction)
+	if door == nil {
+		e.Note = ErrNoDoor.Error()
+	} else if err := door.Use(e.DoorAction, c.Inventory.Items); err != nil {
+		e.Note = err.Error()
+	} else {
+		e.Note = fmt.Sprintf("you %s the %s", e.DoorAction, door.Short)
+	}
+	c.Transmit(NewMessage(e))
+	return nil
+}
+
+func (e *EventLook) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	_, t, err := g.Place(c.Location)
+	if err != nil {
+		return err
+	}
+
+	e.Tile = *g.view(c, t)
+	e.Loose = g.Items.At(c.Location)
+	c.Transmit(NewMessage(e))
+	g.runScript(t.Scripts[OnLook], c, "")
+	return nil
+}
+
+func (e *EventReadNews) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	_, t, err := g.Place(c.Location)
+	if err != nil {
+		return err
+	}
+	switch {
+	case t.NewsBoard == nil:
+		e.Note = "there is no news board here"
+	case len(t.NewsBoard.Headlines) == 0:
+		e.Note = "no news today"
+	default:
+		e.Headlines = t.NewsBoard.Headlines
+	}
+	c.Transmit(NewMessage(e))
+	return nil
+}
+
+func (e *EventPostNews) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	_, t, err := g.Place(c.Location)
+	if err != nil {
+		return err
+	}
+	switch {
+	case t.NewsBoard == nil:
+		e.Note = "there is no news board here"
+	case strings.TrimSpace(e.Text) == "":
+		e.Note = "post what?"
+	default:
+		h := t.NewsBoard.Post(c.Name, strings.TrimSpace(e.Text), g.clock())
+		e.Note = "your news is on the board"
+		g.pushNews(c.Location, c.Ident, []Headline{h})
+	}
+	c.Transmit(NewMessage(e))
+	return nil
+}
+
+func (e *EventExamine) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	_, t, err := g.Place(c.Location)
+	if err != nil {
+		return err
+	}
+	switch {
+	case t.Cybromat != nil && e.Item.Name == "cybromat":
+		e.Interactions = t.Cybromat.Interactions
+	default:
+		item, err := c.Inventory.Items.FindByName(e.Item.Name)
 		if err != nil {
-			return err
+			item, err = g.Items.At(c.Location).FindByName(e.Item.Name)
 		}
-		_, t, err := g.Place(c.Location)
 		if err != nil {
-			return err
+			e.Note = fmt.Sprintf("there is no %s", e.Item.Name)
+			break
 		}
+		e.Item = *item
+		e.Def = g.Catalog.Def(item.Name)
+	}
+	c.Transmit(NewMessage(e))
+	return nil
+}
 
-		e.Tile = *t
-		e.Loose = g.Items.At(c.Location)
-		go c.Transmit(NewMessage(e))
+func (e *EventPickup) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	item, err := g.Items.At(c.Location).FindByName(e.Item.Name)
+	if err != nil {
+		c.Transmit(NewMessage(e))
+		return nil
+	}
+	if g.Catalog.Def(item.Name).Fixed {
+		c.Transmit(NewMessage(&EventInventoryUpdate{
+			Inventory: &c.Inventory,
+			Note:      fmt.Sprintf("the %s cannot be moved", item.Name),
+		}))
+		return nil
+	}
+	// stackable items, like credits, are picked up all at once
+	picked := *item
+	if !g.Catalog.Def(item.Name).Stackable {
+		picked.Count = 1
+	}
+	item.Count -= picked.Count
+	if item.Count == 0 {
+		g.Items.Remove(item)
+	}
+	c.Inventory.AddItem(picked)
+	e.ItemFound = true
+	c.Transmit(NewMessage(&EventInventoryUpdate{Inventory: &c.Inventory}))
+	return nil
+}
 
-	case *EventExamine:
-		c, err := g.Character(e.Ident)
-		if err != nil {
-			return err
+func (e *EventUse) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	item, err := c.Inventory.Items.FindByName(e.Item.Name)
+	if err != nil {
+		// fixed items, e.g. machines, are used where they stand
+		item, err = g.Items.At(c.Location).FindByName(e.Item.Name)
+		if err == nil && !g.Catalog.Def(item.Name).Fixed {
+			err = fmt.Errorf("not carried")
 		}
-		_, t, err := g.Place(c.Location)
-		if err != nil {
+	}
+	if err != nil {
+		e.Note = fmt.Sprintf("you have no %s", e.Item.Name)
+		c.Transmit(NewMessage(e))
+		return nil
+	}
+	def := g.Catalog.Def(item.Name)
+	if script, found := def.Scripts[OnUse]; found {
+		e.Used = true
+		e.Note = fmt.Sprintf("you use %s", item.Name)
+		c.Transmit(NewMessage(e))
+		g.runScript(script, c, "")
+		return nil
+	}
+	effect, found := g.Effects[def.Effect]
+	if !def.Usable || !found {
+		e.Note = fmt.Sprintf("cannot use %s", item.Name)
+		c.Transmit(NewMessage(e))
+		return nil
+	}
+	events, err := effect(g, c, item)
+	if err != nil {
+		e.Note = err.Error()
+		c.Transmit(NewMessage(e))
+		return nil
+	}
+	for _, next := range events { // journaled as part of this event
+		if err := g.handle(next, false); err != nil {
 			return err
 		}
-		if t.Cybromat != nil && e.Item.Name == "cybromat" {
-			e.Interactions = t.Cybromat.Interactions
-		} else {
-			e.Note = fmt.Sprintf("cannot examine %s", e.Item.Name)
-		}
-		go c.Transmit(NewMessage(e))
+	}
+	e.Used = true
+	return nil
+}
 
-	case *EventPickup:
-		c, err := g.Character(e.Ident)
-		if err != nil {
-			return err
+func (e *EventTune) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	c.Tuned = !c.Tuned
+	note := "you tune out of the global channel"
+	if c.Tuned {
+		note = "you tune in to the global channel"
+	}
+	c.Transmit(NewMessage(&EventUse{
+		Item: Item{Name: "communicator"},
+		Note: note,
+	}))
+	return nil
+}
+
+func (e *EventBroadcast) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Inventory.Items.FindByName("communicator"); err != nil || !c.Tuned {
+		return fmt.Errorf("%s not tuned in", c.Ident)
+	}
+	e.Name = c.Name
+	m := NewMessage(e)
+	for _, other := range g.Characters.Tuned() {
+		other.Transmit(m)
+	}
+	return nil
+}
+
+func (e *EventEquip) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	e.Note = g.equip(c, e.Item.Name)
+	c.Transmit(NewMessage(e))
+	c.Transmit(NewMessage(&EventInventoryUpdate{Inventory: &c.Inventory}))
+	return nil
+}
+
+func (e *EventUnequip) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	item, err := c.Inventory.Items.FindByName(e.Item.Name)
+	switch {
+	case err != nil || !item.Equipped:
+		e.Note = fmt.Sprintf("you are not wearing %s", e.Item.Name)
+	default:
+		item.Equipped = false
+		e.Note = fmt.Sprintf("you take off the %s", item.Name)
+	}
+	c.Transmit(NewMessage(e))
+	c.Transmit(NewMessage(&EventInventoryUpdate{Inventory: &c.Inventory}))
+	return nil
+}
+
+func (e *EventBuy) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	e.Note = g.buy(c, e.Item.Name)
+	c.Transmit(NewMessage(&EventInventoryUpdate{
+		Inventory: &c.Inventory,
+		Note:      e.Note,
+	}))
+	return nil
+}
+
+func (e *EventSell) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	e.Note = g.sell(c, e.Item.Name)
+	c.Transmit(NewMessage(&EventInventoryUpdate{
+		Inventory: &c.Inventory,
+		Note:      e.Note,
+	}))
+	return nil
+}
+
+func (e *EventPut) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	e.Note = g.put(c, e.Item.Name, e.Container)
+	c.Transmit(NewMessage(&EventInventoryUpdate{
+		Inventory: &c.Inventory,
+		Note:      e.Note,
+	}))
+	return nil
+}
+
+func (e *EventTake) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	e.Note = g.take(c, e.Item.Name, e.Container)
+	c.Transmit(NewMessage(&EventInventoryUpdate{
+		Inventory: &c.Inventory,
+		Note:      e.Note,
+	}))
+	return nil
+}
+
+func (e *EventTick) AffectGame(g *Game) error {
+	g.now = e.Now
+	health := g.HealthRegen.Points(e.Now)
+	energy := g.EnergyRegen.Points(e.Now)
+	if health > 0 || energy > 0 {
+		for _, c := range g.Characters.All() {
+			c.Regenerate(health, energy)
 		}
-		item, err := g.Items.At(c.Location).FindByName(e.Item.Name)
-		if err != nil {
-			c.Transmit(NewMessage(e))
-			return nil
+	}
+	for _, a := range g.Areas {
+		if a.resetDue(e.Now) {
+			g.resetArea(a)
+		}
+		for _, t := range a.Tiles {
+			for _, v := range t.Vendors {
+				v.Restock(e.Now)
+			}
+			loc := Location{Area: a.Ident, Tile: t.Ident}
+			if t.Ambient != nil && t.Ambient.Due(e.Now) {
+				g.ambient(loc, t.Ambient.Message())
+			}
+			if t.NewsBoard != nil {
+				fresh, err := t.NewsBoard.Refresh()
+				if err != nil {
+					g.Logf("news %s: %v", loc, err)
+				}
+				if len(fresh) > 0 {
+					g.pushNews(loc, "", fresh)
+				}
+			}
+		}
+	}
+	for _, s := range g.Spawns {
+		if s.Due(e.Now) {
+			g.spawn(s, 1)
 		}
-		item.Location.Area = ""
-		item.Location.Tile = ""
-		e.Item.Count = 1
-		c.Inventory.AddItem(e.Item)
-		c.Transmit(NewMessage(&EventInventoryUpdate{&c.Inventory}))
+	}
+	g.fightRound(e.Now)
+	g.respawnNPCs(e.Now)
+	return nil
+}
 
-	case *EventStopGame:
-		// special event that ends the loop, thus we do things here as
-		// no other events should be affecting the game
-		g.Log("shutting down...")
+func (e *EventStopGame) AffectGame(g *Game) error {
+	// special event that ends the loop, thus we do things here as
+	// no other events should be affecting the game
+	g.Log("shutting down...")
 
-		return endEventLoop
+	return endEventLoop
+}
 
-	case *EventDisconnect:
-		// todo
+func (e *EventDisconnect) AffectGame(g *Game) error {
+	c, err := g.Characters.Character(e.Ident)
+	if err != nil {
+		return nil // already left
+	}
+	g.saveCharacter(c)
+	g.stopWalk(c.Ident, nil)
+	g.Characters.Remove(c.Ident)
+	c.closeOutbox()
+	g.Logf("%s disconnected, %v remaining", c.Name, g.Characters.Len())
+	c.TransmitOthers(g, NewMessage(&EventLeave{Ident: c.Ident, Name: c.Name}))
+	return nil
+}
 
-	case interface{ AffectGame(*Game) error }:
-		return e.AffectGame(g)
+func (e *EventAttack) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	e.Note = g.attack(c, e.Target)
+	c.Transmit(NewMessage(e))
+	return nil
+}
 
-	default:
-		return fmt.Errorf("unknown event %T", e)
+func (e *EventQuests) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
 	}
+	e.Quests = g.questLog(c)
+	c.Transmit(NewMessage(e))
+	return nil
+}
+
+func (e *EventStatus) AffectGame(g *Game) error {
+	c, err := g.Character(e.Ident)
+	if err != nil {
+		return err
+	}
+	e.Name = c.Name
+	e.Stats = g.Stats(c)
+	c.Transmit(NewMessage(e))
 	return nil
 }
 
 // Do enques the task and waits for it to complete
 func (g *Game) Do(e Event) error {
 	t := NewTask(e)
-	g.Enqueue(t)
-	t.Done()
-	return t.err
+	if err := g.Enqueue(t); err != nil {
+		return err
+	}
+	return t.Done()
+}
+
+// Enqueue queues the task, waiting at most EnqueueTimeout if the
+// queue is full. Returns ErrQueueFull or ErrGameStopped if the task
+// was not queued.
+func (g *Game) Enqueue(t *Task) error {
+	ctx, cancel := context.WithTimeout(context.Background(), g.EnqueueTimeout)
+	defer cancel()
+	return g.EnqueueContext(ctx, t)
+}
+
+// EnqueueContext queues the task, waiting for room until the context
+// is done.
+func (g *Game) EnqueueContext(ctx context.Context, t *Task) error {
+	g.queueMu.RLock()
+	defer g.queueMu.RUnlock()
+	queue := g.queue()
+	select {
+	case <-g.stopped:
+		return ErrGameStopped
+	default:
+	}
+	select {
+	case queue <- t:
+		return nil
+	default:
+	}
+	select {
+	case <-g.stopped:
+		return ErrGameStopped
+	case queue <- t:
+		return nil
+	case <-ctx.Done():
+		return ErrQueueFull
+	}
+}
+
+// QueueLen returns the number of tasks waiting to be handled.
+func (g *Game) QueueLen() int {
+	return len(g.queue())
+}
+
+func (g *Game) queue() chan *Task {
+	g.queueOnce.Do(func() {
+		g.ch = make(chan *Task, g.MaxTasks)
+		g.stopped = make(chan struct{})
+	})
+	return g.ch
+}
+
+// stop fails tasks still queued and any further tasks.
+func (g *Game) stop() {
+	close(g.stopped)
+	g.queueMu.Lock()
+	defer g.queueMu.Unlock()
+	for {
+		select {
+		case t := <-g.ch:
+			t.setErr(ErrGameStopped)
+		default:
+			return
+		}
+	}
+}
+
+var (
+	ErrQueueFull   = errors.New("game is busy, try again")
+	ErrGameStopped = errors.New("game stopped")
+)
+
+// equip equips the named item from the characters inventory,
+// replacing anything in the same slot. Returns a note for the
+// character.
+func (g *Game) equip(c *Character, n Name) string {
+	item, err := c.Inventory.Items.FindByName(n)
+	if err != nil {
+		return fmt.Sprintf("you have no %s", n)
+	}
+	def := g.Catalog.Def(item.Name)
+	if def.Slot == "" {
+		return fmt.Sprintf("cannot equip %s", item.Name)
+	}
+	if item.Equipped {
+		return fmt.Sprintf("you already wear the %s", item.Name)
+	}
+	for _, other := range c.Inventory.Items {
+		if other.Equipped && g.Catalog.Def(other.Name).Slot == def.Slot {
+			other.Equipped = false
+		}
+	}
+	item.Equipped = true
+	return fmt.Sprintf("you put on the %s", item.Name)
+}
+
+// loadCharacter returns the stored character or a new one if not
+// found. The store is not used while replaying, as the journal
+// already holds what happened to stored characters.
+func (g *Game) loadCharacter(n Name) *Character {
+	if g.Store != nil && !g.replaying {
+		c, err := g.Store.Load(n)
+		if err == nil {
+			if _, _, err := g.Place(c.Location); err != nil {
+				c.Location = startLocation
+			}
+			return c
+		}
+		if !errors.Is(err, ErrNotStored) {
+			g.Log(err)
+		}
+	}
+	return &Character{
+		Name:      n,
+		Location:  startLocation,
+		Inventory: *NewInventory(),
+		Stats:     DefaultStats(),
+	}
+}
+
+func (g *Game) saveCharacter(c *Character) {
+	if g.Store == nil || bool(c.IsBot) || g.replaying {
+		return
+	}
+	if err := g.Store.Save(c); err != nil {
+		g.Log(err)
+	}
+}
+
+// gain gives the character experience, notifying on new level.
+func (g *Game) gain(c *Character, xp int) {
+	if c.Gain(xp) {
+		c.Transmit(NewMessage(&EventStatus{
+			Name:  c.Name,
+			Stats: g.Stats(c),
+			Note:  fmt.Sprintf("you reached level %v", c.Level),
+		}))
+	}
+}
+
+var startLocation = Location{Area: "a1", Tile: "t1"}
+
+const (
+	moveEnergy      = 1
+	visitExperience = 10
+)
+
+// spawn places at most n items at the spawn location without
+// exceeding the spawn count.
+func (g *Game) spawn(s *Spawn, n uint) {
+	var have uint
+	item, err := g.Items.At(s.Location).FindByName(s.Name)
+	if err == nil {
+		have = item.Count
+	}
+	if have >= s.Count {
+		return
+	}
+	if have+n > s.Count {
+		n = s.Count - have
+	}
+	if item != nil {
+		item.Count += n
+		return
+	}
+	g.Items.Add(&Item{
+		Name:     s.Name,
+		Count:    n,
+		Location: s.Location,
+	})
+}
+
+// view returns the tile as seen by the character, safe to send.
+func (g *Game) view(c *Character, t *Tile) *Tile {
+	v := t.View()
+	v.Long = g.describe(c, t)
+	return v
+}
+
+// describe returns the long description of the tile as seen by the
+// character.
+func (g *Game) describe(c *Character, t *Tile) Long {
+	now := g.clock()
+	state := TileState{Now: now, Night: isNight(now)}
+	for _, other := range g.Characters.At(c.Location) {
+		if other.Ident != c.Ident {
+			state.Occupants = append(state.Occupants, other.Name)
+		}
+	}
+	for _, item := range g.Items.At(c.Location) {
+		state.Items = append(state.Items, item.Name)
+	}
+	long, err := t.Describe(state)
+	if err != nil {
+		g.Logf("describe %s: %v", t.Ident, err)
+	}
+	return long
+}
+
+// clock returns the time of the last tick.
+func (g *Game) clock() time.Time {
+	if g.now.IsZero() {
+		return time.Now() // game not running
+	}
+	return g.now
+}
+
+// pushNews sends headlines to players at the location, except the
+// given one.
+func (g *Game) pushNews(loc Location, except Ident, news []Headline) {
+	m := NewMessage(&EventReadNews{Headlines: news})
+	for _, c := range g.Characters.At(loc) {
+		if !c.IsBot && c.Ident != except {
+			c.Transmit(m)
+		}
+	}
+}
+
+// newsBoards returns all news boards in the world.
+func (g *Game) newsBoards() []*NewsBoard {
+	var res []*NewsBoard
+	for _, a := range g.Areas {
+		for _, t := range a.Tiles {
+			if t.NewsBoard != nil {
+				res = append(res, t.NewsBoard)
+			}
+		}
+	}
+	return res
+}
+
+// loadNews reads news sources until ctx is done, the loaded
+// headlines are added on tick.
+func (g *Game) loadNews(ctx context.Context, boards []*NewsBoard) {
+	if len(boards) == 0 {
+		return
+	}
+	ticker := time.NewTicker(g.TickInterval)
+	defer ticker.Stop()
+	for now := time.Now(); ; {
+		for _, b := range boards {
+			b.Load(now)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case now = <-ticker.C:
+		}
+	}
+}
+
+// ambient sends the message to players at the location.
+func (g *Game) ambient(loc Location, msg string) {
+	if msg == "" {
+		return
+	}
+	m := NewMessage(&EventNotice{Text: msg})
+	for _, c := range g.Characters.At(loc) {
+		if !c.IsBot {
+			c.Transmit(m)
+		}
+	}
+}
+
+// resetArea restores the area according to its reset policy.
+func (g *Game) resetArea(a *Area) {
+	g.Logf("reset area %s", a.Ident)
+	if a.Reset.Doors {
+		a.restoreDoors()
+	}
+	if a.Reset.Items {
+		for _, item := range g.Items.InArea(a.Ident) {
+			// keep what players stored, e.g. in lockers
+			if g.Catalog.Def(item.Name).Fixed || len(item.Contents) > 0 {
+				continue
+			}
+			g.Items.Remove(item)
+		}
+		for _, s := range g.Spawns {
+			if s.Location.Area == a.Ident {
+				g.spawn(s, s.Count)
+			}
+		}
+	}
+	if a.Reset.Vendors {
+		for _, t := range a.Tiles {
+			for _, v := range t.Vendors {
+				for _, o := range v.Offers {
+					o.Stock = o.Max
+				}
+			}
+		}
+	}
+}
+
+// container returns the named container from the characters
+// inventory or tile.
+func (g *Game) container(c *Character, n Name) (box, stack *Item, err error) {
+	box, err = c.Inventory.Items.FindByName(n)
+	if err != nil {
+		box, err = g.Items.At(c.Location).FindByName(n)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("there is no %s", n)
+	}
+	if g.Catalog.Def(box.Name).Capacity == 0 {
+		return nil, nil, fmt.Errorf("the %s cannot hold anything", box.Name)
+	}
+	if box.Count > 1 {
+		// fill one at the time so contents don't mix
+		one := &Item{Name: box.Name, Count: 1, Location: box.Location}
+		return one, box, nil
+	}
+	return box, nil, nil
+}
+
+// split moves the filled box out of the stack it was taken from, see
+// container.
+func (g *Game) split(c *Character, box, stack *Item) {
+	if stack == nil {
+		return
+	}
+	stack.Count--
+	for _, item := range c.Inventory.Items {
+		if item == stack {
+			c.Inventory.Items = append(c.Inventory.Items, box)
+			return
+		}
+	}
+	g.Items.Add(box)
+}
+
+// put moves the named item from the characters inventory into a
+// container. Returns a note for the character.
+func (g *Game) put(c *Character, n, container Name) string {
+	item, err := c.Inventory.Items.FindByName(n)
+	if err != nil {
+		return fmt.Sprintf("you have no %s", n)
+	}
+	if item.Equipped {
+		return fmt.Sprintf("unequip the %s first", item.Name)
+	}
+	box, stack, err := g.container(c, container)
+	if err != nil {
+		return err.Error()
+	}
+	moved := *item
+	if !g.Catalog.Def(item.Name).Stackable {
+		moved.Count = 1
+	}
+	if stack == item && moved.Count == item.Count {
+		moved.Count-- // not the box
+	}
+	if box == item || moved.Count == 0 {
+		return fmt.Sprintf("the %s cannot hold itself", box.Name)
+	}
+	free := g.Catalog.Def(box.Name).Capacity - box.Contents.Total()
+	if moved.Count > free {
+		return fmt.Sprintf("the %s is full", box.Name)
+	}
+	g.split(c, box, stack)
+	if item.Count == moved.Count {
+		c.Inventory.Items.Remove(item)
+	} else {
+		item.Count -= moved.Count
+	}
+	inv := Inventory{Items: box.Contents}
+	inv.AddItem(moved)
+	box.Contents = inv.Items
+	return fmt.Sprintf("you put %s in the %s", moved.Name, box.Name)
+}
+
+// take moves the named item from a container into the characters
+// inventory. Returns a note for the character.
+func (g *Game) take(c *Character, n, container Name) string {
+	box, stack, err := g.container(c, container)
+	if err != nil {
+		return err.Error()
+	}
+	item, err := box.Contents.FindByName(n)
+	if err != nil {
+		return fmt.Sprintf("there is no %s in the %s", n, box.Name)
+	}
+	taken := *item
+	if !g.Catalog.Def(item.Name).Stackable {
+		taken.Count = 1
+	}
+	g.split(c, box, stack)
+	item.Count -= taken.Count
+	if item.Count == 0 {
+		box.Contents.Remove(item)
+	}
+	c.Inventory.AddItem(taken)
+	return fmt.Sprintf("you take %s from the %s", taken.Name, box.Name)
+}
+
+// buy one of the named item from a vendor on the characters tile.
+// Returns a note for the character.
+func (g *Game) buy(c *Character, n Name) string {
+	_, t, err := g.Place(c.Location)
+	if err != nil {
+		return err.Error()
+	}
+	v, o, err := t.Vendors.Selling(n)
+	switch {
+	case err != nil:
+		return err.Error()
+	case o.Stock == 0:
+		return fmt.Sprintf("the %s is out of %s", v.Short, o.Name)
+	case c.Inventory.Count(Credit) < o.Price:
+		return fmt.Sprintf("%s costs %v credits, you cannot afford it", o.Name, o.Price)
+	}
+	c.Inventory.RemoveItem(Credit, o.Price)
+	c.Inventory.AddItem(Item{Name: o.Name, Count: 1})
+	o.Stock--
+	return fmt.Sprintf("you buy %s for %v credits", o.Name, o.Price)
+}
+
+// sell one of the named item to a vendor on the characters tile.
+// Returns a note for the character.
+func (g *Game) sell(c *Character, n Name) string {
+	item, err := c.Inventory.Items.FindByName(n)
+	if err != nil {
+		return fmt.Sprintf("you have no %s", n)
+	}
+	if item.Equipped {
+		return fmt.Sprintf("unequip the %s first", item.Name)
+	}
+	if len(item.Contents) > 0 {
+		return fmt.Sprintf("empty the %s first", item.Name)
+	}
+	if !g.Catalog.Def(item.Name).Tradeable {
+		return fmt.Sprintf("%s cannot be sold", item.Name)
+	}
+	_, t, err := g.Place(c.Location)
+	if err != nil {
+		return err.Error()
+	}
+	_, o, err := t.Vendors.Selling(item.Name)
+	if err != nil {
+		return err.Error()
+	}
+	name, price := item.Name, o.BuyBack()
+	c.Inventory.RemoveItem(name, 1)
+	c.Inventory.AddItem(Item{Name: Credit, Count: price})
+	o.Stock++
+	return fmt.Sprintf("you sell %s for %v credits", name, price)
+}
+
+// Stats returns the characters effective stats, base stats changed
+// by all modifiers.
+func (g *Game) Stats(c *Character) Stats {
+	s := c.Stats
+	for _, m := range g.Modifiers {
+		m.Modify(g, c, &s)
+	}
+	return s
 }
 
-func (g *Game) Enqueue(t *Task) {
-	defer func() {
-		// handle closed channel, ie. game stopped
-		if err := recover(); err != nil {
-			t.setErr(fmt.Errorf("game stopped, event dropped"))
+// walk moves the character along the path using regular move
+// events, so others see it approach and go away. Stops if a move is
+// refused.
+func (g *Game) walk(w *walk, id Ident, path []Direction) {
+	defer g.stopWalk(id, w)
+	for _, d := range path {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-time.After(g.StepDelay):
 		}
-	}()
-	g.ch <- t
+		e := &EventMove{Ident: id, Direction: d, walk: w}
+		if err := g.Do(e); err != nil || e.Note != "" {
+			return
+		}
+	}
+}
+
+// walk is one ongoing goto, there is at most one per character.
+type walk struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// startWalk cancels any ongoing walk of the character before
+// starting a new one.
+func (g *Game) startWalk(id Ident, path []Direction) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &walk{ctx: ctx, cancel: cancel}
+	g.walksMu.Lock()
+	if old, found := g.walks[id]; found {
+		old.cancel()
+	}
+	g.walks[id] = w
+	g.walksMu.Unlock()
+	go g.walk(w, id, path)
+}
+
+// stopWalk cancels the walk of the character, any walk if w is nil.
+func (g *Game) stopWalk(id Ident, w *walk) {
+	g.walksMu.Lock()
+	defer g.walksMu.Unlock()
+	if current, found := g.walks[id]; found && (w == nil || w == current) {
+		current.cancel()
+		delete(g.walks, id)
+	}
+}
+
+// walking returns true if the move is part of the characters
+// current walk. Moves made by the player stop the walk.
+func (g *Game) walking(id Ident, w *walk) bool {
+	if w == nil {
+		g.stopWalk(id, nil)
+		return true
+	}
+	g.walksMu.Lock()
+	defer g.walksMu.Unlock()
+	return g.walks[id] == w
 }
 
+var errWalkStopped = errors.New("walk stopped")
+
 // Place returns the Location as area and tile.
 func (g *Game) Place(loc Location) (a *Area, t *Tile, err error) {
 	if a, err = g.Area(loc.Area); err != nil {
@@ -249,7 +1302,7 @@ func (g *Game) Character(id Ident) (*Character, error) {
 var endEventLoop = fmt.Errorf("end event loop")
 
 func link(t *Tile, d Direction) (Ident, error) {
-	if d < 0 || int(d) > len(t.Nav) {
+	if d < 0 || int(d) >= len(t.Nav) {
 		return "", fmt.Errorf("bad direction")
 	}
 	return t.Nav[int(d)], nil
@@ -263,20 +1316,38 @@ type Characters interface {
 	Remove(Ident)
 	Len() int
 	At(Location) []*Character
+	Tuned() []*Character
+	All() []*Character
+
+	// Move sets the location of the character, always use it
+	// instead of changing the location directly.
+	Move(*Character, Location)
+
+	// AreaOf returns the area the character is in
+	AreaOf(Ident) (Ident, bool)
 }
 
 func NewCharactersMap() *CharactersMap {
 	return &CharactersMap{
 		Index: make(map[Ident]*Character),
+		at:    make(map[Location]map[Ident]*Character),
 	}
 }
 
+// CharactersMap is safe for concurrent use, though each character
+// should only be changed by one goroutine at a time.
 type CharactersMap struct {
-	Index   map[Ident]*Character
-	idCount int
+	Index    map[Ident]*Character
+	idCount  int
+	botCount int // own sequence so players get the same ident on replay
+
+	mu sync.RWMutex
+	at map[Location]map[Ident]*Character
 }
 
 func (me *CharactersMap) Character(id Ident) (*Character, error) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
 	c, found := me.Index[id]
 	if !found {
 		return nil, fmt.Errorf("character %q not found", id)
@@ -285,25 +1356,99 @@ func (me *CharactersMap) Character(id Ident) (*Character, error) {
 }
 
 func (me *CharactersMap) Add(c *Character) {
-	me.idCount++
-	c.Ident = Ident(fmt.Sprintf("char%02v", me.idCount))
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	if c.IsBot {
+		me.botCount++
+		c.Ident = Ident(fmt.Sprintf("bot%02v", me.botCount))
+	} else {
+		me.idCount++
+		c.Ident = Ident(fmt.Sprintf("char%02v", me.idCount))
+	}
 	me.Index[c.Ident] = c
+	me.place(c)
 }
 
 func (me *CharactersMap) Remove(id Ident) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	if c, found := me.Index[id]; found {
+		me.unplace(c)
+	}
 	delete(me.Index, id)
 }
 
+func (me *CharactersMap) Move(c *Character, to Location) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.unplace(c)
+	c.Location = to
+	me.place(c)
+}
+
+func (me *CharactersMap) AreaOf(id Ident) (Ident, bool) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	c, found := me.Index[id]
+	if !found {
+		return "", false
+	}
+	return c.Location.Area, true
+}
+
+func (me *CharactersMap) place(c *Character) {
+	here, found := me.at[c.Location]
+	if !found {
+		here = make(map[Ident]*Character)
+		me.at[c.Location] = here
+	}
+	here[c.Ident] = c
+}
+
+func (me *CharactersMap) unplace(c *Character) {
+	here := me.at[c.Location]
+	delete(here, c.Ident)
+	if len(here) == 0 {
+		delete(me.at, c.Location)
+	}
+}
+
 func (me *CharactersMap) Len() int {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
 	return len(me.Index)
 }
 
 func (me *CharactersMap) At(loc Location) []*Character {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	here := me.at[loc]
+	res := make([]*Character, 0, len(here))
+	for _, c := range here {
+		res = append(res, c)
+	}
+	return res
+}
+
+// Tuned returns characters listening to the global channel.
+func (me *CharactersMap) Tuned() []*Character {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
 	res := make([]*Character, 0)
 	for _, c := range me.Index {
-		if c.Location.Equal(loc) {
+		if c.Tuned {
 			res = append(res, c)
 		}
 	}
 	return res
 }
+
+func (me *CharactersMap) All() []*Character {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	res := make([]*Character, 0, len(me.Index))
+	for _, c := range me.Index {
+		res = append(res, c)
+	}
+	return res
+}
@@ -0,0 +1,480 @@
+package cible
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGame_Stats(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	if err := g.AffectGame(j); err != nil {
+		t.Fatal(err)
+	}
+	c := j.Character
+	g.Characters.Move(c, Location{Area: "a1", Tile: "t3"}) // where the gloves are
+	g.AffectGame(&EventPickup{Ident: c.Ident, Item: Item{Name: "gloves"}})
+	g.AffectGame(&EventEquip{Ident: c.Ident, Item: Item{Name: "gloves"}})
+	if got := g.Stats(c); got.Strength != c.Strength+1 {
+		t.Error("equipped gloves gave no strength", got)
+	}
+	g.AffectGame(&EventUnequip{Ident: c.Ident, Item: Item{Name: "gloves"}})
+	if got := g.Stats(c); got.Strength != c.Strength {
+		t.Error("unequipped gloves still give strength", got)
+	}
+}
+
+func TestGame_buyAndSell(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+
+	buy := &EventBuy{Ident: c.Ident, Item: Item{Name: "cola"}}
+	g.AffectGame(buy)
+	if c.Inventory.Count("cola") != 0 {
+		t.Fatal("bought cola where there is no vendor")
+	}
+
+	g.Characters.Move(c, Location{Area: "a1", Tile: "t4"}) // west stateroom
+	g.AffectGame(buy)
+	if c.Inventory.Count("cola") != 1 || c.Inventory.Count(Credit) != 195 {
+		t.Fatal(buy.Note)
+	}
+	sell := &EventSell{Ident: c.Ident, Item: Item{Name: "cola"}}
+	g.AffectGame(sell)
+	if c.Inventory.Count("cola") != 0 || c.Inventory.Count(Credit) != 197 {
+		t.Error(sell.Note)
+	}
+	sell = &EventSell{Ident: c.Ident, Item: Item{Name: "digipass"}}
+	g.AffectGame(sell)
+	if c.Inventory.Count("digipass") != 1 {
+		t.Error("sold digipass")
+	}
+
+	// empty the noodles
+	_, tile, _ := g.Place(c.Location)
+	_, o, _ := tile.Vendors.Selling("noodles")
+	o.Stock = 0
+	buy = &EventBuy{Ident: c.Ident, Item: Item{Name: "noodles"}}
+	g.AffectGame(buy)
+	if c.Inventory.Count("noodles") != 0 {
+		t.Error("bought sold out noodles")
+	}
+}
+
+func TestVendor_Restock(t *testing.T) {
+	o := &Offer{Name: "water", Max: 2}
+	v := &Vendor{Offers: Offers{o}, RestockEvery: time.Minute}
+	now := time.Now()
+	v.Restock(now)
+	v.Restock(now.Add(time.Second))
+	if o.Stock != 0 {
+		t.Fatal("restocked too early")
+	}
+	for i := 1; i < 5; i++ {
+		v.Restock(now.Add(time.Duration(i) * time.Minute))
+	}
+	if o.Stock != o.Max {
+		t.Error("stock", o.Stock)
+	}
+}
+
+func TestGame_respawn(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+	g.Characters.Move(c, Location{Area: "a1", Tile: "t9"})
+	loc := c.Location
+
+	now := time.Now()
+	g.AffectGame(&EventTick{Now: now}) // start clocks
+	g.AffectGame(&EventPickup{Ident: c.Ident, Item: Item{Name: "ball"}})
+	if len(g.Items.At(loc)) != 0 {
+		t.Fatal("ball still there")
+	}
+	g.AffectGame(&EventTick{Now: now.Add(time.Minute)})
+	if len(g.Items.At(loc)) != 0 {
+		t.Fatal("ball respawned too early")
+	}
+	g.AffectGame(&EventTick{Now: now.Add(10 * time.Minute)})
+	if len(g.Items.At(loc)) != 1 {
+		t.Fatal("ball did not respawn")
+	}
+
+	// unlock the rest room door, should be locked after reset
+	_, tile, _ := g.Place(Location{Area: "a1", Tile: "t8"})
+	door := tile.Doors.Door(N)
+	door.Locked = false
+	// store something in the locker, should survive the reset
+	g.Characters.Move(c, Location{Area: "a1", Tile: "t8"})
+	g.AffectGame(&EventPut{Ident: c.Ident, Item: Item{Name: "digipass"}, Container: "locker"})
+
+	g.AffectGame(&EventTick{Now: now.Add(7 * time.Hour)})
+	if !door.Locked {
+		t.Error("door not locked after reset")
+	}
+	locker, err := g.Items.At(c.Location).FindByName("locker")
+	if err != nil || len(locker.Contents) != 1 {
+		t.Error("locker emptied by reset", locker)
+	}
+	if n := len(g.Items.At(c.Location)); n != 1 {
+		t.Error("expected one locker after reset, got", n)
+	}
+}
+
+func TestGame_containers(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+	g.Characters.Move(c, Location{Area: "a1", Tile: "t8"}) // rest room with a locker
+
+	pickup := &EventPickup{Ident: c.Ident, Item: Item{Name: "locker"}}
+	g.AffectGame(pickup)
+	if c.Inventory.Count("locker") != 0 || pickup.ItemFound {
+		t.Fatal("picked up locker")
+	}
+	put := &EventPut{Ident: c.Ident, Item: Item{Name: "credit"}, Container: "locker"}
+	g.AffectGame(put)
+	if c.Inventory.Count(Credit) != 200 {
+		t.Fatal("200 credits fit in locker:", put.Note)
+	}
+	put = &EventPut{Ident: c.Ident, Item: Item{Name: "digipass"}, Container: "locker"}
+	g.AffectGame(put)
+	locker, _ := g.Items.At(c.Location).FindByName("locker")
+	if len(locker.Contents) != 1 || c.Inventory.Count("digipass") != 0 {
+		t.Fatal(put.Note)
+	}
+	take := &EventTake{Ident: c.Ident, Item: Item{Name: "digipass"}, Container: "locker"}
+	g.AffectGame(take)
+	if len(locker.Contents) != 0 || c.Inventory.Count("digipass") != 1 {
+		t.Fatal(take.Note)
+	}
+
+	put = &EventPut{Ident: c.Ident, Item: Item{Name: "digipass"}, Container: "ball"}
+	g.AffectGame(put)
+	if c.Inventory.Count("digipass") != 1 {
+		t.Error("put digipass in a ball")
+	}
+
+	// stacks are split only when filled
+	c.Inventory.AddItem(Item{Name: "bag", Count: 2})
+	put = &EventPut{Ident: c.Ident, Item: Item{Name: "credit"}, Container: "bag"}
+	g.AffectGame(put)
+	if bags := countStacks(c.Inventory.Items, "bag"); bags != 1 {
+		t.Fatal("failed put split bags:", put.Note)
+	}
+	put = &EventPut{Ident: c.Ident, Item: Item{Name: "digipass"}, Container: "bag"}
+	g.AffectGame(put)
+	if bags := countStacks(c.Inventory.Items, "bag"); bags != 2 {
+		t.Fatal("bags not split:", put.Note)
+	}
+	empty, _ := c.Inventory.Items.FindByName("bag")
+	c.Inventory.Items.Remove(empty) // keep the filled one
+	sell := &EventSell{Ident: c.Ident, Item: Item{Name: "bag"}}
+	g.AffectGame(sell)
+	if !strings.Contains(sell.Note, "empty") {
+		t.Error("sold bag with contents:", sell.Note)
+	}
+}
+
+func countStacks(items Items, n Name) int {
+	var count int
+	for _, item := range items {
+		if item.Name == n {
+			count++
+		}
+	}
+	return count
+}
+
+func TestGame_progression(t *testing.T) {
+	g := NewGame()
+	g.Store, _ = NewDirStore(t.TempDir())
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+
+	g.AffectGame(&EventMove{Ident: c.Ident, Direction: N})
+	g.AffectGame(&EventMove{Ident: c.Ident, Direction: S})
+	if c.Experience != visitExperience {
+		t.Error("experience", c.Experience)
+	}
+	if c.Energy != c.MaxEnergy-2*moveEnergy {
+		t.Error("energy", c.Energy)
+	}
+	c.Energy = 0
+	m := &EventMove{Ident: c.Ident, Direction: N}
+	g.AffectGame(m)
+	if m.Note == "" {
+		t.Error("moved without energy")
+	}
+	now := time.Now()
+	g.AffectGame(&EventTick{Now: now})
+	g.AffectGame(&EventTick{Now: now.Add(time.Second)})
+	if c.Energy != 1 {
+		t.Error("no energy regenerated", c.Energy)
+	}
+
+	// stats are kept between games
+	g.AffectGame(&EventLeave{Ident: c.Ident})
+	j = &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	if j.Character.Experience != visitExperience {
+		t.Error("experience lost", j.Character.Experience)
+	}
+}
+
+func TestRegen_Points(t *testing.T) {
+	r := Regen{Every: time.Second}
+	now := time.Now()
+	if p := r.Points(now); p != 0 {
+		t.Error("first call", p)
+	}
+	if p := r.Points(now.Add(2500 * time.Millisecond)); p != 2 {
+		t.Error("after 2.5s", p)
+	}
+	if p := r.Points(now.Add(3 * time.Second)); p != 1 {
+		t.Error("remainder lost", p)
+	}
+}
+
+func TestGame_joinTwice(t *testing.T) {
+	g := NewGame()
+	g.AffectGame(&EventJoinGame{Player: Player{Name: "John"}})
+	err := g.AffectGame(&EventJoinGame{Player: Player{Name: "John"}})
+	if !errors.Is(err, ErrNameTaken) {
+		t.Error("joined twice with same name:", err)
+	}
+}
+
+func TestStats_Gain(t *testing.T) {
+	s := DefaultStats()
+	if s.Gain(99) {
+		t.Error("level up too early")
+	}
+	if !s.Gain(1) || s.Level != 2 {
+		t.Error("no level up", s)
+	}
+	if s.Gain(400); s.Level != 3 {
+		t.Error("level", s.Level)
+	}
+}
+
+func TestGame_combat(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+
+	a := &EventAttack{Ident: c.Ident, Target: "drone"}
+	g.AffectGame(a)
+	if !strings.Contains(a.Note, "no drone") {
+		t.Fatal("attacked drone far away:", a.Note)
+	}
+
+	droneAlive := func() bool {
+		for _, npc := range g.npcs {
+			if npc.Name == "drone" {
+				return true
+			}
+		}
+		return false
+	}
+	g.Characters.Move(c, Location{Area: "a1", Tile: "t7"}) // where the drone is
+	g.AffectGame(a)
+	now := time.Now()
+	for i := 0; i < 100 && droneAlive(); i++ {
+		now = now.Add(time.Second)
+		g.AffectGame(&EventTick{Now: now})
+	}
+	if droneAlive() {
+		t.Fatal("drone not defeated, player health", c.Health)
+	}
+	if c.Experience < defeatExperience {
+		t.Error("no experience for defeating drone")
+	}
+	if _, err := g.Items.At(c.Location).FindByName(Credit); err != nil {
+		t.Error("drone dropped no credits")
+	}
+	if len(g.fights) > 0 {
+		t.Error("fights remain", g.fights)
+	}
+
+	g.AffectGame(&EventTick{Now: now.Add(3 * time.Minute)})
+	if !droneAlive() {
+		t.Error("drone not respawned")
+	}
+}
+
+func TestGame_defeat(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+	tr := &recorder{}
+	c.out = NewOutbox(tr, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &walk{ctx: ctx, cancel: cancel}
+	g.walks[c.Ident] = w
+
+	g.Characters.Move(c, Location{Area: "a1", Tile: "t7"})
+	g.defeat(&Character{}, c, time.Now())
+	if g.walking(c.Ident, w) || ctx.Err() == nil {
+		t.Error("still walking after defeat")
+	}
+	c.out.Close() // flushes queued messages
+	time.Sleep(10 * time.Millisecond)
+	var move EventMove
+	for _, m := range tr.sent() {
+		if m.EventName == "move" {
+			Decode(&move, &m)
+		}
+	}
+	if move.Tile == nil {
+		t.Fatal("no move after defeat")
+	}
+	if move.Tile.Scripts != nil || strings.Contains(string(move.Tile.Long), "{{") {
+		t.Error("raw tile sent", move.Tile)
+	}
+}
+
+func TestGame_quests(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+	if len(c.Quests) != len(g.Quests) {
+		t.Fatal("quests not assigned")
+	}
+
+	g.AffectGame(&EventMove{Ident: c.Ident, Direction: N}) // news room
+	g.AffectGame(&EventSay{Ident: c.Ident, Text: "hello"})
+	g.AffectGame(&EventMove{Ident: c.Ident, Direction: S})
+	g.AffectGame(&EventMove{Ident: c.Ident, Direction: SW}) // tech room
+	g.AffectGame(&EventUse{Ident: c.Ident, Item: Item{Name: "cybromat"}})
+
+	log := g.questLog(c)
+	if !log[0].Completed {
+		t.Fatalf("%+v", log[0])
+	}
+	if got := c.Inventory.Count(Credit); got != 250 {
+		t.Error("reward not given", got)
+	}
+	if log[1].Completed {
+		t.Error("second quest completed")
+	}
+}
+
+func TestCharactersMap(t *testing.T) {
+	m := NewCharactersMap()
+	here := Location{Area: "a1", Tile: "t1"}
+	there := Location{Area: "a1", Tile: "t2"}
+	john := &Character{Name: "John", Location: here}
+	eve := &Character{Name: "Eve", Location: here}
+	m.Add(john)
+	m.Add(eve)
+	if len(m.At(here)) != 2 {
+		t.Fatal("add not indexed", m.At(here))
+	}
+	m.Move(john, there)
+	if len(m.At(here)) != 1 || len(m.At(there)) != 1 || john.Location != there {
+		t.Error("move not indexed", m.At(here), m.At(there))
+	}
+	m.Remove(john.Ident)
+	if len(m.At(there)) != 0 {
+		t.Error("remove not indexed", m.At(there))
+	}
+}
+
+func TestItemsMap(t *testing.T) {
+	m := NewItemsMap()
+	here := Location{Area: "a1", Tile: "t1"}
+	ball := &Item{Name: "ball", Location: here}
+	cola := &Item{Name: "cola", Location: Location{Area: "a2", Tile: "t1"}}
+	m.Add(ball, cola)
+	if len(m.At(here)) != 1 || len(m.InArea("a2")) != 1 || m.Len() != 2 {
+		t.Fatal("add not indexed", m.All())
+	}
+	m.Remove(ball)
+	m.Remove(ball)
+	if len(m.At(here)) != 0 || m.Len() != 1 {
+		t.Error("remove not indexed", m.All())
+	}
+}
+
+func TestGame_Enqueue(t *testing.T) {
+	g := NewGame()
+	g.MaxTasks = 1
+	g.EnqueueTimeout = 0
+	first := NewTask(&EventLook{})
+	if err := g.Enqueue(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Enqueue(NewTask(&EventLook{})); !errors.Is(err, ErrQueueFull) {
+		t.Error("expected ErrQueueFull, got", err)
+	}
+	g.EnqueueTimeout = 10 * time.Millisecond
+	start := time.Now()
+	if err := g.Enqueue(NewTask(&EventLook{})); !errors.Is(err, ErrQueueFull) {
+		t.Error("expected ErrQueueFull, got", err)
+	}
+	if time.Since(start) < g.EnqueueTimeout {
+		t.Error("did not wait for room")
+	}
+	if n := g.QueueLen(); n != 1 {
+		t.Error("queue len", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g.Run(ctx)
+	first.Done() // handled or failed, but done
+	if err := g.Do(&EventLook{}); !errors.Is(err, ErrGameStopped) {
+		t.Error("expected ErrGameStopped, got", err)
+	}
+
+	m := NewErrorMessage("1", ErrQueueFull)
+	if err := m.CheckError(); err == nil || err.Error() != ErrQueueFull.Error() {
+		t.Error("CheckError", err)
+	}
+}
+
+func Test_unlockEffect(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+	_, tile, _ := g.Place(c.Location)
+	tile.Doors = Doors{
+		N: &Door{Short: "gate", Locked: true, Key: "digipass"},
+		S: &Door{Short: "hatch", Locked: true, Key: "digipass"},
+	}
+	events, err := unlockEffect(g, c, &Item{Name: "digipass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range events {
+		g.AffectGame(e)
+	}
+	for d, door := range tile.Doors {
+		if door.Locked {
+			t.Error(Direction(d), "still locked")
+		}
+	}
+}
+
+func TestCharactersMap_Add(t *testing.T) {
+	m := NewCharactersMap()
+	m.Add(&Character{Name: "drone", IsBot: true})
+	c := &Character{Name: "John"}
+	m.Add(c)
+	if c.Ident != "char01" {
+		t.Error("player ident depends on bots:", c.Ident)
+	}
+}
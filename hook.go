@@ -0,0 +1,54 @@
+package cible
+
+// EventHandler applies the event to the game.
+type EventHandler func(g *Game, e Event) error
+
+// Middleware wraps event handling, e.g. for logging or metrics. It
+// decides if and when next is called.
+type Middleware func(next EventHandler) EventHandler
+
+// BeforeHook is called before an event affects the game, returning
+// an error vetoes the event, e.g. when a permission is missing.
+// Before hooks are not called during Game.Replay, as replayed events
+// were already accepted.
+type BeforeHook func(g *Game, e Event) error
+
+// AfterHook observes the result of an event affecting the game, err
+// is nil if the event was accepted.
+type AfterHook func(g *Game, e Event, err error)
+
+// DefaultAfterHooks returns the after hooks used by NewGame.
+func DefaultAfterHooks() []AfterHook {
+	return []AfterHook{
+		questHook,
+	}
+}
+
+// handleEvent is the innermost handler, running hooks around the
+// event and journaling it if accepted.
+func handleEvent(g *Game, e Event, journal bool) error {
+	for _, before := range g.BeforeHooks {
+		if g.replaying {
+			break
+		}
+		if err := before(g, e); err != nil {
+			return err
+		}
+	}
+	journal = journal && g.Journal != nil && journaled(e)
+	var m Message
+	if journal { // before the event is changed by the game
+		m = eventMessage(e)
+	}
+	err := e.AffectGame(g)
+	for _, after := range g.AfterHooks {
+		after(g, e, err)
+	}
+	if err != nil {
+		return err
+	}
+	if journal {
+		g.journal(m)
+	}
+	return nil
+}
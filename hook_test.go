@@ -0,0 +1,104 @@
+package cible
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGame_hooks(t *testing.T) {
+	g := NewGame()
+	var calls []string
+	logged := func(next EventHandler) EventHandler {
+		return func(g *Game, e Event) error {
+			calls = append(calls, "middleware")
+			return next(g, e)
+		}
+	}
+	noMoves := func(g *Game, e Event) error {
+		calls = append(calls, "before")
+		if _, ok := e.(*EventMove); ok {
+			return errNoMoves
+		}
+		return nil
+	}
+	var results []error
+	observe := func(g *Game, e Event, err error) {
+		calls = append(calls, "after")
+		results = append(results, err)
+	}
+	g.Middlewares = append(g.Middlewares, logged)
+	g.BeforeHooks = append(g.BeforeHooks, noMoves)
+	g.AfterHooks = append(g.AfterHooks, observe)
+
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	if err := g.AffectGame(j); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, " "); got != "middleware before after" {
+		t.Error("unexpected order:", got)
+	}
+
+	calls = calls[:0]
+	loc := j.Character.Location
+	err := g.AffectGame(&EventMove{Ident: j.Ident, Direction: N})
+	if !errors.Is(err, errNoMoves) {
+		t.Error("move not vetoed:", err)
+	}
+	if j.Character.Location != loc {
+		t.Error("vetoed move affected game")
+	}
+	if got := strings.Join(calls, " "); got != "middleware before" {
+		t.Error("after hooks called for vetoed event:", got)
+	}
+
+	g.AffectGame(&EventLook{Ident: "nobody"})
+	if len(results) != 2 || results[1] == nil {
+		t.Error("after hook did not observe failure", results)
+	}
+
+	// replayed events were accepted when journaled
+	var buf bytes.Buffer
+	NewJournal(&buf).Record(time.Now(), NewMessage(
+		&EventMove{Ident: j.Ident, Direction: S},
+	))
+	if _, err := g.Replay(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if j.Character.Location == loc {
+		t.Error("replayed move vetoed")
+	}
+}
+
+func TestGame_hooksOnEffects(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	cid := j.Ident
+	g.AffectGame(&EventMove{Ident: cid, Direction: N})
+	g.AffectGame(&EventMove{Ident: cid, Direction: E}) // by the rest room
+	var doors int
+	g.BeforeHooks = append(g.BeforeHooks, func(g *Game, e Event) error {
+		if _, ok := e.(*EventDoor); ok {
+			doors++
+			return errNoDoors
+		}
+		return nil
+	})
+
+	use := &EventUse{Ident: cid, Item: Item{Name: "digipass"}}
+	if err := g.AffectGame(use); !errors.Is(err, errNoDoors) {
+		t.Error("use not vetoed:", err)
+	}
+	_, tile, _ := g.Place(j.Character.Location)
+	if doors != 1 || use.Used || !tile.Doors.Door(S).Locked {
+		t.Error("digipass bypassed door hook")
+	}
+}
+
+var (
+	errNoMoves = errors.New("no moves")
+	errNoDoors = errors.New("no doors")
+)
@@ -1,6 +1,10 @@
 package cible
 
-import "errors"
+import (
+	"errors"
+	"strings"
+	"sync"
+)
 
 type Items []*Item
 
@@ -14,20 +18,187 @@ func (me *Items) At(loc Location) Items {
 	return res
 }
 
+// InArea returns items placed anywhere in the given area.
+func (me *Items) InArea(id Ident) Items {
+	res := make(Items, 0)
+	for _, item := range *me {
+		if item.Location.Area == id {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
 func (me Items) FindByName(n Name) (*Item, error) {
 	for _, item := range me {
-		if item.Name == item.Name {
+		if strings.EqualFold(string(item.Name), string(n)) {
 			return item, nil
 		}
 	}
 	return nil, ErrItemNotFound
 }
 
+// Remove removes the item from the list, if present.
+func (me *Items) Remove(v *Item) {
+	for i, item := range *me {
+		if item == v {
+			*me = append((*me)[:i], (*me)[i+1:]...)
+			return
+		}
+	}
+}
+
+// Total returns the sum of all item counts.
+func (me Items) Total() uint {
+	var sum uint
+	for _, item := range me {
+		sum += item.Count
+	}
+	return sum
+}
+
 var ErrItemNotFound = errors.New("item not found")
 
+// NewItemsMap returns an empty ItemsMap.
+func NewItemsMap() *ItemsMap {
+	return &ItemsMap{
+		at: make(map[Location]Items),
+	}
+}
+
+// ItemsMap holds items placed in the world indexed by location. The
+// location of an item must not change while it's in the map. Safe for
+// concurrent use.
+type ItemsMap struct {
+	mu  sync.RWMutex
+	at  map[Location]Items
+	len int
+}
+
+func (me *ItemsMap) Add(items ...*Item) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	for _, item := range items {
+		me.at[item.Location] = append(me.at[item.Location], item)
+		me.len++
+	}
+}
+
+// Remove removes the item, if present.
+func (me *ItemsMap) Remove(v *Item) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	here, found := me.at[v.Location]
+	if !found {
+		return
+	}
+	before := len(here)
+	here.Remove(v)
+	me.len -= before - len(here)
+	if len(here) == 0 {
+		delete(me.at, v.Location)
+		return
+	}
+	me.at[v.Location] = here
+}
+
+// At returns the items at the given location.
+func (me *ItemsMap) At(loc Location) Items {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	return append(make(Items, 0, len(me.at[loc])), me.at[loc]...)
+}
+
+// InArea returns items placed anywhere in the given area.
+func (me *ItemsMap) InArea(id Ident) Items {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	res := make(Items, 0)
+	for loc, here := range me.at {
+		if loc.Area == id {
+			res = append(res, here...)
+		}
+	}
+	return res
+}
+
+// All returns all items
+func (me *ItemsMap) All() Items {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	res := make(Items, 0, me.len)
+	for _, here := range me.at {
+		res = append(res, here...)
+	}
+	return res
+}
+
+func (me *ItemsMap) Len() int {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	return me.len
+}
+
+// Item is an instance of an item definition found in the catalog by
+// name.
 type Item struct {
 	Name
 	Count uint
 
 	Location // if it's not in a persons inventory
+
+	Equipped bool
+	Contents Items // if it's a container
+}
+
+// ----------------------------------------
+
+// ItemDef describes one kind of item.
+type ItemDef struct {
+	Name
+	Description string
+	Weight      uint // grams
+	Value       uint // credits
+
+	Stackable bool // picked up, dropped and traded as a whole
+	Usable    bool
+	Tradeable bool
+
+	Effect Name  // triggered when used, see Game.Effects
+	Slot         // where it's equipped, empty if not equippable
+	Bonus  Stats // given when equipped
+
+	Capacity uint // number of items it holds if a container
+	Fixed    bool // cannot be picked up, e.g. lockers
+
+	Scripts // e.g. OnUse, makes the item usable
+}
+
+// Catalog of item definitions by name
+type Catalog map[Name]*ItemDef
+
+// Add adds the definitions, names are case insensitive.
+func (me Catalog) Add(defs ...*ItemDef) {
+	for _, def := range defs {
+		me[lower(def.Name)] = def
+	}
+}
+
+// Lookup returns the named definition.
+func (me Catalog) Lookup(n Name) (*ItemDef, bool) {
+	def, found := me[lower(n)]
+	return def, found
+}
+
+// Def returns the named definition or a plain definition with only
+// the name if not found.
+func (me Catalog) Def(n Name) *ItemDef {
+	if def, found := me.Lookup(n); found {
+		return def
+	}
+	return &ItemDef{Name: n}
+}
+
+func lower(n Name) Name {
+	return Name(strings.ToLower(string(n)))
 }
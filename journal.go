@@ -0,0 +1,143 @@
+package cible
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// NewJournal returns a journal appending entries to w, one json
+// object per line.
+func NewJournal(w io.Writer) *Journal {
+	return &Journal{enc: json.NewEncoder(w)}
+}
+
+// Journal is an append-only log of events accepted by the game. Use
+// Game.Replay to reconstruct a game from it.
+type Journal struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// Record appends the message as accepted at the given time.
+func (me *Journal) Record(t time.Time, m Message) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	return me.enc.Encode(JournalEntry{Time: t, Message: m})
+}
+
+type JournalEntry struct {
+	Time time.Time
+	Message
+}
+
+// ----------------------------------------
+
+// Replay applies all events in the journal to the game, which should
+// not be running. Events are not journaled again and players are not
+// notified. Characters are neither loaded from nor saved to the
+// Store and players still in the game when the journal ends, e.g.
+// after a crash, are removed so they can join again. Ticks are
+// driven by the entry times, as ticks are not journaled. Events
+// depending on chance, e.g. combat, may not turn out the same.
+// Returns the number of replayed events.
+func (g *Game) Replay(r io.Reader) (int, error) {
+	g.replaying = true
+	defer func() { g.replaying = false }()
+	defer g.removeOffline()
+
+	var n int
+	var last time.Time // of last tick
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(s.Bytes(), &entry); err != nil {
+			return n, fmt.Errorf("replay entry %v: %w", n+1, err)
+		}
+		e, found := newJournaled(entry.EventName)
+		if !found {
+			return n, fmt.Errorf("replay entry %v: unknown event %s", n+1, entry.EventName)
+		}
+		if err := Decode(e, &entry.Message); err != nil {
+			return n, fmt.Errorf("replay entry %v: %w", n+1, err)
+		}
+		n++
+		g.tickUntil(&last, entry.Time)
+		if err := g.AffectGame(e); err != nil {
+			g.Logf("replay %s: %v", entry.String(), err)
+		}
+	}
+	return n, s.Err()
+}
+
+// tickUntil ticks from last up to now, like the running game.
+func (g *Game) tickUntil(last *time.Time, now time.Time) {
+	if last.IsZero() || g.TickInterval <= 0 {
+		*last = now
+		g.AffectGame(&EventTick{Now: now}) // start the clocks
+		return
+	}
+	for next := last.Add(g.TickInterval); !next.After(now); next = next.Add(g.TickInterval) {
+		g.AffectGame(&EventTick{Now: next})
+		*last = next
+	}
+}
+
+// removeOffline removes players not connected.
+func (g *Game) removeOffline() {
+	for _, c := range g.Characters.All() {
+		if c.IsBot || c.out != nil {
+			continue
+		}
+		for id, target := range g.fights {
+			if id == c.Ident || target == c.Ident {
+				delete(g.fights, id)
+			}
+		}
+		g.stopWalk(c.Ident, nil)
+		g.Characters.Remove(c.Ident)
+	}
+}
+
+// newJournaled returns a new instance of the named event. Older
+// journals name events by Go type, e.g. cible.EventMove.
+func newJournaled(name string) (Event, bool) {
+	if v, found := typeNames[name]; found {
+		name = v
+	}
+	v, found := NewEvent(name)
+	e, ok := v.(Event)
+	return e, found && ok
+}
+
+// journaled returns false for events Replay recreates, i.e. ticks.
+func journaled(e Event) bool {
+	_, tick := e.(*EventTick)
+	return !tick
+}
+
+// journal records the message if the game has a journal.
+func (g *Game) journal(m Message) {
+	if g.Journal == nil || g.replaying {
+		return
+	}
+	if err := g.Journal.Record(time.Now(), m); err != nil {
+		g.Log(err)
+	}
+}
+
+// eventMessage is like NewMessage for an event of unknown type.
+func eventMessage(e Event) Message {
+	var buf bytes.Buffer
+	gob.NewEncoder(&buf).Encode(e)
+	return Message{
+		EventName: eventName(e),
+		Body:      buf.Bytes(),
+	}
+}
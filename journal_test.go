@@ -0,0 +1,136 @@
+package cible
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGame_Replay(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewGame()
+	g.Journal = NewJournal(&buf)
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	cid := j.Ident
+	g.AffectGame(&EventMove{Ident: cid, Direction: S})
+	g.AffectGame(&EventMove{Ident: cid, Direction: E})
+	g.AffectGame(&EventPickup{Ident: cid, Item: Item{Name: "ball"}})
+	g.AffectGame(&EventUse{Ident: cid, Item: Item{Name: "communicator"}})
+	g.AffectGame(&EventTick{Now: time.Now()})
+	g.AffectGame(&EventMove{Ident: "nobody", Direction: N}) // refused
+
+	if got := strings.Count(buf.String(), "\n"); got != 5 {
+		t.Fatalf("expected 5 journaled events, got %v\n%s", got, buf.String())
+	}
+
+	replayed := NewGame()
+	n, err := replayed.Replay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Error("replayed", n)
+	}
+	if _, err := replayed.Items.At(j.Character.Location).FindByName("ball"); err == nil {
+		t.Error("ball not picked up in replay")
+	}
+
+	// journal ends without John leaving, as after a crash
+	if _, err := replayed.Character(cid); err == nil {
+		t.Error("offline character left in game")
+	}
+	if err := replayed.AffectGame(&EventJoinGame{Player: Player{Name: "John"}}); err != nil {
+		t.Error("cannot join after replay:", err)
+	}
+
+	t.Run("store untouched", func(t *testing.T) {
+		store, err := NewDirStore(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		g := NewGame()
+		g.Store = store
+		g.Journal = NewJournal(&buf)
+		j := &EventJoinGame{Player: Player{Name: "Eve"}}
+		g.AffectGame(j)
+		g.AffectGame(&EventMove{Ident: j.Ident, Direction: S})
+		g.AffectGame(&EventMove{Ident: j.Ident, Direction: E})
+		g.AffectGame(&EventPickup{Ident: j.Ident, Item: Item{Name: "ball"}})
+		g.AffectGame(&EventLeave{Ident: j.Ident})
+		saved, _ := store.Load("Eve")
+
+		replayed := NewGame()
+		replayed.Store = store
+		if _, err := replayed.Replay(&buf); err != nil {
+			t.Fatal(err)
+		}
+		got, _ := store.Load("Eve")
+		if got.Location != saved.Location || got.Inventory.Count("ball") != 1 {
+			t.Error("store changed by replay", got.Location, got.Inventory.Items)
+		}
+	})
+
+	t.Run("old event names", func(t *testing.T) {
+		var buf bytes.Buffer
+		g := NewGame()
+		g.Journal = NewJournal(&buf)
+		j := &EventJoinGame{Player: Player{Name: "Eve"}}
+		g.AffectGame(j)
+		g.AffectGame(&EventMove{Ident: j.Ident, Direction: S})
+		g.AffectGame(&EventMove{Ident: j.Ident, Direction: E})
+		g.AffectGame(&EventPickup{Ident: j.Ident, Item: Item{Name: "ball"}})
+		old := strings.NewReplacer(
+			`"join-game"`, `"cible.EventJoinGame"`,
+			`"move"`, `"cible.EventMove"`,
+			`"pickup"`, `"cible.EventPickup"`,
+		).Replace(buf.String())
+
+		replayed := NewGame()
+		if n, err := replayed.Replay(strings.NewReader(old)); err != nil || n != 4 {
+			t.Fatal(n, err)
+		}
+		if _, err := replayed.Items.At(j.Character.Location).FindByName("ball"); err == nil {
+			t.Error("old journal not replayed")
+		}
+	})
+
+	t.Run("ticks", func(t *testing.T) {
+		var buf bytes.Buffer
+		journal := NewJournal(&buf)
+		now := time.Now()
+		journal.Record(now, NewMessage(&EventJoinGame{Player: Player{Name: "Eve"}}))
+		for i := 1; i <= 150; i++ { // more than energy allows without regen
+			d := S
+			if i%2 == 0 {
+				d = N
+			}
+			journal.Record(
+				now.Add(time.Duration(i)*time.Second),
+				NewMessage(&EventMove{Ident: "char01", Direction: d}),
+			)
+		}
+		replayed := NewGame()
+		var refused int
+		replayed.AfterHooks = append(replayed.AfterHooks, func(g *Game, e Event, err error) {
+			if m, ok := e.(*EventMove); ok && m.Note != "" {
+				refused++
+			}
+		})
+		if _, err := replayed.Replay(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if refused > 0 {
+			t.Error("refused moves, no regeneration in replay:", refused)
+		}
+	})
+
+	t.Run("bad entry", func(t *testing.T) {
+		_, err := NewGame().Replay(strings.NewReader("{jibberish\n"))
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
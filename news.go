@@ -0,0 +1,166 @@
+package cible
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewNewsBoard returns a board showing at most max headlines.
+func NewNewsBoard(max int) *NewsBoard {
+	return &NewsBoard{
+		Max:   max,
+		Every: time.Minute,
+	}
+}
+
+// NewsBoard is a fixture showing headlines, read from a local source
+// and posted by players.
+type NewsBoard struct {
+	Headlines []Headline // newest first
+	Max       int
+
+	// Every is how often the source is read
+	Every time.Duration
+
+	source string
+	seen   map[string]bool // headlines in the source when last read
+
+	mu      sync.Mutex // guards below, set by Load
+	last    time.Time
+	loaded  []Headline
+	loadErr error
+}
+
+// Headline on a news board
+type Headline struct {
+	Time   time.Time
+	Author Name // empty if from source
+	Text   string
+}
+
+// SetSource sets the file or directory headlines are read from. Each
+// non empty line is one headline, in a directory all files are read.
+func (me *NewsBoard) SetSource(v string) { me.source = v }
+
+// Post adds a headline written by a player.
+func (me *NewsBoard) Post(author Name, text string, now time.Time) Headline {
+	h := Headline{Time: now, Author: author, Text: text}
+	me.add(h)
+	return h
+}
+
+// Load reads the source if due, the headlines are added on next
+// Refresh. Load is called outside the game loop as it reads from
+// disk.
+func (me *NewsBoard) Load(now time.Time) {
+	if me.source == "" {
+		return
+	}
+	me.mu.Lock()
+	due := me.last.IsZero() || now.Sub(me.last) >= me.Every
+	if due {
+		me.last = now
+	}
+	me.mu.Unlock()
+	if !due {
+		return
+	}
+	all, err := readHeadlines(me.source)
+	me.mu.Lock()
+	me.loaded, me.loadErr = all, err
+	me.mu.Unlock()
+}
+
+// Refresh adds loaded headlines not seen before, returning them.
+func (me *NewsBoard) Refresh() ([]Headline, error) {
+	me.mu.Lock()
+	all, err := me.loaded, me.loadErr
+	me.loaded, me.loadErr = nil, nil
+	me.mu.Unlock()
+	if all == nil {
+		return nil, err
+	}
+	// only remember what is in the source, so seen does not grow
+	// beyond it
+	seen := make(map[string]bool, len(all))
+	var fresh []Headline
+	for _, h := range all {
+		seen[h.Text] = true
+		if me.seen[h.Text] {
+			continue
+		}
+		me.add(h)
+		fresh = append(fresh, h)
+	}
+	me.seen = seen
+	return fresh, err
+}
+
+func (me *NewsBoard) add(h Headline) {
+	me.Headlines = append([]Headline{h}, me.Headlines...)
+	sort.SliceStable(me.Headlines, func(i, j int) bool {
+		return me.Headlines[i].Time.After(me.Headlines[j].Time)
+	})
+	if me.Max > 0 && len(me.Headlines) > me.Max {
+		me.Headlines = me.Headlines[:me.Max]
+	}
+}
+
+// readHeadlines from a file or all files in a directory. Headlines
+// are timestamped with the modification time of their file.
+func readHeadlines(source string) ([]Headline, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{source}
+	if info.IsDir() {
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return nil, err
+		}
+		files = files[:0]
+		for _, e := range entries {
+			if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			files = append(files, filepath.Join(source, e.Name()))
+		}
+	}
+	var res []Headline
+	for _, filename := range files {
+		v, err := readHeadlineFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v...)
+	}
+	return res, nil
+}
+
+func readHeadlineFile(filename string) ([]Headline, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	info, err := fh.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var res []Headline
+	s := bufio.NewScanner(fh)
+	for s.Scan() {
+		text := strings.TrimSpace(s.Text())
+		if text == "" {
+			continue
+		}
+		res = append(res, Headline{Time: info.ModTime(), Text: text})
+	}
+	return res, s.Err()
+}
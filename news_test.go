@@ -0,0 +1,78 @@
+package cible
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewsBoard_Refresh(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "today.txt")
+	os.WriteFile(filename, []byte("Ship docked\n\nDrone escaped\n"), 0644)
+
+	b := NewNewsBoard(2)
+	b.SetSource(dir)
+	now := time.Now()
+	if fresh, _ := b.Refresh(); len(fresh) != 0 {
+		t.Error("refreshed before loaded", fresh)
+	}
+	b.Load(now)
+	fresh, err := b.Refresh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fresh) != 2 {
+		t.Fatal("expected 2 fresh headlines", fresh)
+	}
+	b.Load(now)
+	if fresh, _ := b.Refresh(); len(fresh) != 0 {
+		t.Error("loaded before due", fresh)
+	}
+	b.Load(now.Add(b.Every))
+	if fresh, _ := b.Refresh(); len(fresh) != 0 {
+		t.Error("same news again", fresh)
+	}
+
+	// seen headlines are forgotten when removed from the source
+	os.WriteFile(filename, []byte("Drone escaped\n"), 0644)
+	b.Load(now.Add(2 * b.Every))
+	b.Refresh()
+	if len(b.seen) != 1 {
+		t.Error("seen not limited to source", b.seen)
+	}
+
+	b.Post("John", "Lost my ball", now.Add(time.Hour))
+	if len(b.Headlines) != 2 || b.Headlines[0].Author != "John" {
+		t.Error("post not first or max not kept", b.Headlines)
+	}
+
+	b.SetSource(filepath.Join(dir, "missing"))
+	b.Load(now.Add(3 * b.Every))
+	if _, err := b.Refresh(); err == nil {
+		t.Error("expected error for missing source")
+	}
+}
+
+func TestGame_news(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+
+	read := &EventReadNews{Ident: c.Ident}
+	g.AffectGame(read)
+	if read.Note == "" {
+		t.Error("expected no news board note")
+	}
+
+	g.Characters.Move(c, Location{Area: "a1", Tile: "t6"}) // news room
+	post := &EventPostNews{Ident: c.Ident, Text: "Hello spaceport"}
+	g.AffectGame(post)
+	read = &EventReadNews{Ident: c.Ident}
+	g.AffectGame(read)
+	if len(read.Headlines) != 1 || read.Headlines[0].Text != "Hello spaceport" {
+		t.Error(post.Note, read.Headlines)
+	}
+}
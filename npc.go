@@ -0,0 +1,35 @@
+package cible
+
+import "time"
+
+// NPC defines a non player character placed in the world.
+type NPC struct {
+	Name
+	Location
+	Stats
+	Loot Items // dropped when defeated
+
+	Hostile      bool   // attacks players who come near
+	Says         string // when spoken to
+	RespawnAfter time.Duration
+
+	Scripts // OnSay when spoken to
+}
+
+// newCharacter returns a bot character based on the definition.
+func (me *NPC) newCharacter() *Character {
+	return &Character{
+		Name:     me.Name,
+		Location: me.Location,
+		IsBot:    true,
+		Stats:    me.Stats,
+	}
+}
+
+type NPCs []*NPC
+
+// respawn is a defeated NPC waiting to be placed again.
+type respawn struct {
+	*NPC
+	at time.Time
+}
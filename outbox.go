@@ -0,0 +1,85 @@
+package cible
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// NewOutbox returns an outbox buffering at most size messages and
+// starts writing them to the transmitter.
+func NewOutbox(tr Transmitter, size int) *Outbox {
+	me := &Outbox{
+		tr: tr,
+		ch: make(chan Message, size),
+	}
+	go me.run()
+	return me
+}
+
+// Outbox is the outgoing queue of one character. Messages are
+// transmitted in the order they are sent by a single goroutine.
+type Outbox struct {
+	tr Transmitter
+
+	mu     sync.Mutex
+	ch     chan Message
+	closed bool
+}
+
+// Send queues the message without blocking. If the buffer is full
+// the client is considered too slow and is disconnected.
+func (me *Outbox) Send(m Message) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	if me.closed {
+		return ErrOutboxClosed
+	}
+	select {
+	case me.ch <- m:
+		return nil
+	default:
+		me.close()
+		me.disconnect()
+		return ErrSlowClient
+	}
+}
+
+// Close stops the outbox once queued messages are transmitted.
+func (me *Outbox) Close() {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.close()
+}
+
+func (me *Outbox) run() {
+	for m := range me.ch {
+		if err := me.tr.Transmit(m); err != nil {
+			me.Close()
+			me.disconnect()
+			return
+		}
+	}
+}
+
+// close must be called with the lock held
+func (me *Outbox) close() {
+	if me.closed {
+		return
+	}
+	me.closed = true
+	close(me.ch)
+}
+
+// disconnect closes the transmitter if possible, which in turn ends
+// the connection on the server.
+func (me *Outbox) disconnect() {
+	if c, ok := me.tr.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+var (
+	ErrOutboxClosed = errors.New("outbox closed")
+	ErrSlowClient   = errors.New("client too slow, disconnected")
+)
@@ -0,0 +1,84 @@
+package cible
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOutbox(t *testing.T) {
+	t.Run("keeps order", func(t *testing.T) {
+		tr := &recorder{}
+		out := NewOutbox(tr, 100)
+		for i := 0; i < 100; i++ {
+			if err := out.Send(Message{Id: strconv.Itoa(i)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		out.Close()
+		time.Sleep(10 * time.Millisecond)
+		for i, m := range tr.sent() {
+			if m.Id != strconv.Itoa(i) {
+				t.Fatalf("message %v out of order, got %v", i, m.Id)
+			}
+		}
+		if err := out.Send(Message{}); !errors.Is(err, ErrOutboxClosed) {
+			t.Error("send after close:", err)
+		}
+	})
+
+	t.Run("disconnects slow client", func(t *testing.T) {
+		tr := &recorder{block: make(chan struct{})}
+		defer close(tr.block)
+		out := NewOutbox(tr, 1)
+		var err error
+		for i := 0; i < 3 && err == nil; i++ {
+			err = out.Send(Message{})
+		}
+		if !errors.Is(err, ErrSlowClient) {
+			t.Error("expected slow client, got", err)
+		}
+		if !tr.isClosed() {
+			t.Error("slow client not disconnected")
+		}
+	})
+}
+
+type recorder struct {
+	block chan struct{}
+
+	mu     sync.Mutex
+	msgs   []Message
+	closed bool
+}
+
+func (me *recorder) Transmit(v any) error {
+	if me.block != nil {
+		<-me.block
+	}
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.msgs = append(me.msgs, v.(Message))
+	return nil
+}
+
+func (me *recorder) sent() []Message {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	return me.msgs
+}
+
+func (me *recorder) Close() error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.closed = true
+	return nil
+}
+
+func (me *recorder) isClosed() bool {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	return me.closed
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"math/rand"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -131,6 +132,174 @@ func Test_badEvents(t *testing.T) {
 
 }
 
+func TestGame_doors(t *testing.T) {
+	g := startNewGame(t)
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	if err := g.Do(j); err != nil {
+		t.Fatal(err)
+	}
+	cid := j.Ident
+	g.Do(&EventMove{Ident: cid, Direction: N})
+	g.Do(&EventMove{Ident: cid, Direction: E})
+
+	// rest room door is locked
+	m := &EventMove{Ident: cid, Direction: S}
+	g.Do(m)
+	if m.Note == "" || m.Location.Tile != "t7" {
+		t.Fatal("moved through locked door", m.Location)
+	}
+	open := &EventDoor{Ident: cid, Direction: S, DoorAction: OpenDoor}
+	g.Do(open)
+	if !strings.Contains(open.Note, "locked") {
+		t.Error("opened locked door:", open.Note)
+	}
+	for _, a := range []DoorAction{UnlockDoor, OpenDoor} {
+		e := &EventDoor{Ident: cid, Direction: S, DoorAction: a}
+		g.Do(e)
+		t.Log(e.Note)
+	}
+	m = &EventMove{Ident: cid, Direction: S}
+	g.Do(m)
+	if m.Note != "" || m.Location.Tile != "t8" {
+		t.Error("cannot pass open door:", m.Note)
+	}
+
+	// no door in that direction
+	e := &EventDoor{Ident: cid, Direction: E, DoorAction: OpenDoor}
+	g.Do(e)
+	if e.Note != ErrNoDoor.Error() {
+		t.Error(e.Note)
+	}
+}
+
+func TestGame_goto(t *testing.T) {
+	g := startNewGame(t)
+	g.StepDelay = 0
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	if err := g.Do(j); err != nil {
+		t.Fatal(err)
+	}
+	e := &EventGoto{Ident: j.Ident, Destination: "news room"}
+	if err := g.Do(e); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Path) != 1 {
+		t.Fatal("unexpected path", e.Path, e.Note)
+	}
+	var at Ident
+	arrived := eventually(func() bool {
+		look := &EventLook{Ident: j.Ident}
+		g.Do(look)
+		at = look.Tile.Ident
+		return at == "t6"
+	})
+	if !arrived {
+		t.Error("did not walk to news room, at", at)
+	}
+
+	e = &EventGoto{Ident: j.Ident, Destination: "nowhere"}
+	g.Do(e)
+	if len(e.Path) != 0 || e.Note == "" {
+		t.Error("walking to nowhere", e.Note)
+	}
+}
+
+func TestGame_gotoStopped(t *testing.T) {
+	g := startNewGame(t)
+	g.StepDelay = 10 * time.Millisecond
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	if err := g.Do(j); err != nil {
+		t.Fatal(err)
+	}
+	g.Do(&EventGoto{Ident: j.Ident, Destination: "north-east stateroom"})
+	g.Do(&EventMove{Ident: j.Ident, Direction: S}) // stops walking
+	pause("50ms")
+	look := &EventLook{Ident: j.Ident}
+	g.Do(look)
+	if got := look.Tile.Ident; got != "t2" {
+		t.Error("kept walking after move, at", got)
+	}
+}
+
+func TestGame_items(t *testing.T) {
+	g := startNewGame(t)
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	if err := g.Do(j); err != nil {
+		t.Fatal(err)
+	}
+	cid := j.Ident
+	g.Do(&EventMove{Ident: cid, Direction: S})
+	g.Do(&EventMove{Ident: cid, Direction: E})
+
+	x := &EventExamine{Ident: cid, Item: Item{Name: "Ball"}}
+	g.Do(x)
+	if x.Def == nil || x.Def.Description == "" {
+		t.Error("examine ball on floor:", x.Note)
+	}
+
+	p := &EventPickup{Ident: cid, Item: Item{Name: "ball"}}
+	g.Do(p)
+	if !p.ItemFound {
+		t.Fatal("ball not found")
+	}
+	p = &EventPickup{Ident: cid, Item: Item{Name: "ball"}}
+	g.Do(p)
+	if p.ItemFound {
+		t.Error("picked up same ball twice")
+	}
+
+	x = &EventExamine{Ident: cid, Item: Item{Name: "digipass"}}
+	g.Do(x)
+	if x.Def == nil || !x.Def.Usable {
+		t.Error("examine digipass in inventory:", x.Note)
+	}
+	x = &EventExamine{Ident: cid, Item: Item{Name: "unicorn"}}
+	g.Do(x)
+	if x.Note == "" {
+		t.Error("examined missing unicorn")
+	}
+}
+
+func TestGame_useAndEquip(t *testing.T) {
+	g := startNewGame(t)
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	if err := g.Do(j); err != nil {
+		t.Fatal(err)
+	}
+	cid := j.Ident
+
+	// not tuned in yet
+	if err := g.Do(&EventBroadcast{Ident: cid, Text: "hi"}); err == nil {
+		t.Error("broadcast without tuning in")
+	}
+	g.Do(&EventUse{Ident: cid, Item: Item{Name: "communicator"}})
+	if err := g.Do(&EventBroadcast{Ident: cid, Text: "hi"}); err != nil {
+		t.Error(err)
+	}
+
+	use := &EventUse{Ident: cid, Item: Item{Name: "ball"}}
+	g.Do(use)
+	if use.Note == "" {
+		t.Error("used missing ball")
+	}
+
+	// unlock rest room door using digipass
+	g.Do(&EventMove{Ident: cid, Direction: N})
+	g.Do(&EventMove{Ident: cid, Direction: E})
+	g.Do(&EventUse{Ident: cid, Item: Item{Name: "digipass"}})
+	open := &EventDoor{Ident: cid, Direction: S, DoorAction: OpenDoor}
+	g.Do(open)
+	if strings.Contains(open.Note, "locked") {
+		t.Error("digipass did not unlock door")
+	}
+
+	eq := &EventEquip{Ident: cid, Item: Item{Name: "digipass"}}
+	g.Do(eq)
+	if !strings.Contains(eq.Note, "cannot") {
+		t.Error("equipped digipass:", eq.Note)
+	}
+}
+
 func Test_cancelGame(t *testing.T) {
 	g := NewGame()
 	ctx, cancel := context.WithCancel(context.Background())
@@ -175,18 +344,20 @@ func BenchmarkMoveCharacter_1000_player(b *testing.B) {
 	defer g.Do(&EventStopGame{})
 
 	// Join all players first
-	for i := 0; i < 1000; i++ {
+	ids := make([]Ident, 1000)
+	for i := range ids {
 		var p Player
 		p.SetName(fmt.Sprintf("John%v", i))
 		e := &EventJoinGame{Player: p}
 		if err := g.Do(e); err != nil {
 			b.Fatal(err)
 		}
+		ids[i] = e.Ident
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		cid := Ident(fmt.Sprintf("John%v", rand.Intn(1000)))
+		cid := ids[rand.Intn(len(ids))]
 		g.Do(&EventMove{Ident: cid, Direction: N})
 		g.Do(&EventMove{Ident: cid, Direction: S})
 	}
@@ -216,6 +387,19 @@ func startNewGame(t testing.TB) *Game {
 	return g
 }
 
+// eventually returns true as soon as cond does, false if it doesn't
+// within a second.
+func eventually(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		pause("1ms")
+	}
+	return false
+}
+
 func pause(v string) {
 	dur, err := time.ParseDuration(v)
 	if err != nil {
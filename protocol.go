@@ -2,7 +2,10 @@ package cible
 
 import (
 	"encoding/gob"
+	"errors"
+	"fmt"
 	"io"
+	"time"
 )
 
 type Protocol interface {
@@ -28,3 +31,79 @@ type Encoder interface {
 type Decoder interface {
 	Decode(v any) error
 }
+
+// ProtocolVersion is increased on changes breaking older clients or
+// servers, e.g. removed events or fields changing type. Adding
+// events and fields is compatible.
+const ProtocolVersion = 1
+
+// handshakeTimeout is how long each side waits for the others hello.
+var handshakeTimeout = time.Second
+
+// Hello is the first message sent in both directions when a client
+// connects.
+type Hello struct {
+	Version int
+	Events  []string // supported events and notifications
+
+	Compression string // supported, e.g. Flate, empty for none
+}
+
+// NewHelloMessage returns the hello message of this side,
+// compression is Flate or empty for none.
+func NewHelloMessage(compression string) Message {
+	m := NewMessage(&Hello{
+		Version:     ProtocolVersion,
+		Events:      EventNames(),
+		Compression: compression,
+	})
+	m.EventName = "hello"
+	return m
+}
+
+// Check returns ErrIncompatible if the other side speaks another
+// protocol version.
+func (me *Hello) Check() error {
+	if me.Version != ProtocolVersion {
+		return fmt.Errorf(
+			"%w %v, expected %v", ErrIncompatible, me.Version, ProtocolVersion,
+		)
+	}
+	return nil
+}
+
+// Supports returns true if the named event is known to the other
+// side.
+func (me *Hello) Supports(name string) bool {
+	switch name {
+	case "hello", "error":
+		return true
+	}
+	for _, n := range me.Events {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readHello decodes the hello message, returning ErrNoHello if it's
+// something else.
+func readHello(m *Message) (*Hello, error) {
+	if err := m.CheckError(); err != nil {
+		return nil, err
+	}
+	if m.EventName != "hello" {
+		return nil, fmt.Errorf("%w, got %s", ErrNoHello, m.EventName)
+	}
+	var hello Hello
+	if err := Decode(&hello, m); err != nil {
+		return nil, err
+	}
+	return &hello, hello.Check()
+}
+
+var (
+	ErrIncompatible = errors.New("incompatible protocol version")
+	ErrNoHello      = errors.New("expected hello")
+)
@@ -0,0 +1,211 @@
+package cible
+
+import "fmt"
+
+// Quest is something for players to pursue, completed when all
+// objectives are done.
+type Quest struct {
+	Ident
+	Title
+	Objectives []Objective
+
+	// given on completion
+	Reward     Items
+	Experience int
+}
+
+// Objective of a quest, e.g. visit a tile or pick up an item.
+type Objective struct {
+	Short // e.g. visit the news room
+	ObjectiveKind
+
+	Location // for Visit
+	Target   Name
+}
+
+type ObjectiveKind int
+
+const (
+	Visit  ObjectiveKind = iota // Location
+	Pickup                      // item named Target
+	Talk                        // say something near npc named Target
+	Use                         // item or fixture named Target
+)
+
+// Done returns true if the event fulfills the objective for the
+// character.
+func (me *Objective) Done(g *Game, c *Character, e Event) bool {
+	switch e := e.(type) {
+	case *EventMove:
+		return me.ObjectiveKind == Visit && e.Note == "" &&
+			me.Location.Equal(e.Location)
+
+	case *EventPickup:
+		return me.ObjectiveKind == Pickup && bool(e.ItemFound) &&
+			lower(e.Item.Name) == lower(me.Target)
+
+	case *EventUse:
+		return me.ObjectiveKind == Use && e.Used &&
+			lower(e.Item.Name) == lower(me.Target)
+
+	case *EventSay:
+		if me.ObjectiveKind != Talk {
+			return false
+		}
+		for _, other := range g.Characters.At(c.Location) {
+			if other.IsBot && lower(other.Name) == lower(me.Target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type Quests []*Quest
+
+// Quest returns the quest with the given ident
+func (me Quests) Quest(id Ident) (*Quest, error) {
+	for _, q := range me {
+		if q.Ident == id {
+			return q, nil
+		}
+	}
+	return nil, fmt.Errorf("quest %q not found", id)
+}
+
+// QuestProgress of one character
+type QuestProgress struct {
+	Ident            // of quest
+	Done      []bool // per objective
+	Completed bool
+}
+
+// questHook tracks quest progress of accepted events
+func questHook(g *Game, e Event, err error) {
+	if err == nil {
+		g.trackQuests(e)
+	}
+}
+
+// trackQuests updates the quest progress of the character who
+// triggered the event, rewarding completed quests.
+func (g *Game) trackQuests(e Event) {
+	id, ok := senderOf(e)
+	if !ok {
+		return
+	}
+	c, err := g.Character(id)
+	if err != nil || c.IsBot {
+		return
+	}
+	for i := range c.Quests {
+		p := &c.Quests[i]
+		if p.Completed {
+			continue
+		}
+		q, err := g.Quests.Quest(p.Ident)
+		if err != nil {
+			continue
+		}
+		for len(p.Done) < len(q.Objectives) {
+			p.Done = append(p.Done, false) // objectives added later
+		}
+		var changed bool
+		for j := range q.Objectives {
+			if !p.Done[j] && q.Objectives[j].Done(g, c, e) {
+				p.Done[j] = true
+				changed = true
+				c.Transmit(NewMessage(&EventQuestUpdate{
+					Title: q.Title,
+					Note:  fmt.Sprintf("done: %s", q.Objectives[j].Short),
+				}))
+			}
+		}
+		if !changed || !allDone(p.Done) {
+			continue
+		}
+		p.Completed = true
+		for _, item := range q.Reward {
+			c.Inventory.AddItem(*item)
+		}
+		c.Transmit(NewMessage(&EventQuestUpdate{
+			Title: q.Title,
+			Note:  "quest completed!",
+		}))
+		c.Transmit(NewMessage(&EventInventoryUpdate{Inventory: &c.Inventory}))
+		g.gain(c, q.Experience)
+	}
+}
+
+// assignQuests gives the character all quests it does not have yet.
+func (g *Game) assignQuests(c *Character) {
+next:
+	for _, q := range g.Quests {
+		for _, p := range c.Quests {
+			if p.Ident == q.Ident {
+				continue next
+			}
+		}
+		c.Quests = append(c.Quests, QuestProgress{
+			Ident: q.Ident,
+			Done:  make([]bool, len(q.Objectives)),
+		})
+	}
+}
+
+// questLog returns the quests of the character as shown to the
+// player.
+func (g *Game) questLog(c *Character) []QuestStatus {
+	res := make([]QuestStatus, 0, len(c.Quests))
+	for _, p := range c.Quests {
+		q, err := g.Quests.Quest(p.Ident)
+		if err != nil {
+			continue
+		}
+		s := QuestStatus{Title: q.Title, Completed: p.Completed}
+		for i, o := range q.Objectives {
+			s.Objectives = append(s.Objectives, ObjectiveStatus{
+				Short: o.Short,
+				Done:  i < len(p.Done) && p.Done[i],
+			})
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+type QuestStatus struct {
+	Title
+	Objectives []ObjectiveStatus
+	Completed  bool
+}
+
+type ObjectiveStatus struct {
+	Short
+	Done bool
+}
+
+func allDone(v []bool) bool {
+	for _, done := range v {
+		if !done {
+			return false
+		}
+	}
+	return true
+}
+
+// senderOf returns the ident of the character that sent the event,
+// if any.
+func senderOf(e Event) (Ident, bool) {
+	switch e := e.(type) {
+	case *EventMove:
+		return e.Ident, true
+	case *EventPickup:
+		return e.Ident, true
+	case *EventUse:
+		return e.Ident, true
+	case *EventSay:
+		return e.Ident, true
+	}
+	return "", false
+}
@@ -0,0 +1,347 @@
+package cible
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Script is a small program attached to tiles, items or npcs and run
+// by the game when triggered. One statement per line
+//
+//	# comment
+//	tell TEXT        message the character
+//	tellroom TEXT    message everyone else on the tile
+//	give ITEM [N]    add items to the characters inventory
+//	take ITEM [N]    remove items from the characters inventory
+//	spawn ITEM [N]   place items on the tile
+//	stop             end the script
+//	if COND ... [else ...] end
+//	repeat N ... end
+//
+// where COND is one of
+//
+//	has ITEM         character has the item
+//	random N         N percent chance
+//	said WORD        spoken text contains word, only for say trigger
+//	not COND
+//
+// $name and $said in text are replaced with the characters name and
+// what was said.
+type Script string
+
+// Scripts by trigger
+type Scripts map[Trigger]Script
+
+type Trigger string
+
+const (
+	OnEnter Trigger = "enter"
+	OnLook  Trigger = "look"
+	OnUse   Trigger = "use"
+	OnSay   Trigger = "say"
+)
+
+// ScriptLimits stops scripts running for too long.
+type ScriptLimits struct {
+	MaxOps  int
+	Timeout time.Duration
+}
+
+// MaxRepeat is the largest count a repeat statement may have.
+const MaxRepeat = 1000
+
+// Compile parses the script, returning the first syntax error.
+func (me Script) Compile() ([]stmt, error) {
+	var lines []string
+	s := bufio.NewScanner(strings.NewReader(string(me)))
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	p := &parser{lines: lines}
+	body, end, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if end != "" {
+		return nil, fmt.Errorf("line %v: unexpected %s", p.i, end)
+	}
+	return body, nil
+}
+
+type stmt struct {
+	line int
+	cmd  string
+	args []string
+
+	body, orElse []stmt
+}
+
+type parser struct {
+	lines []string
+	i     int
+}
+
+// block parses statements until end of script, else or end. Returns
+// the word that ended the block, empty if end of script.
+func (p *parser) block() ([]stmt, string, error) {
+	var res []stmt
+	for p.i < len(p.lines) {
+		fields := strings.Fields(p.lines[p.i])
+		p.i++
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		s := stmt{line: p.i, cmd: fields[0], args: fields[1:]}
+		switch s.cmd {
+		case "else", "end":
+			return res, s.cmd, nil
+
+		case "tell", "tellroom", "stop":
+
+		case "give", "take", "spawn":
+			if len(s.args) == 0 || len(s.args) > 2 {
+				return nil, "", fmt.Errorf("line %v: %s ITEM [N]", s.line, s.cmd)
+			}
+			if len(s.args) == 2 {
+				if _, err := strconv.Atoi(s.args[1]); err != nil {
+					return nil, "", fmt.Errorf("line %v: %w", s.line, err)
+				}
+			}
+
+		case "repeat":
+			if len(s.args) != 1 {
+				return nil, "", fmt.Errorf("line %v: repeat N", s.line)
+			}
+			n, err := strconv.Atoi(s.args[0])
+			if err != nil {
+				return nil, "", fmt.Errorf("line %v: %w", s.line, err)
+			}
+			if n < 0 || n > MaxRepeat {
+				return nil, "", fmt.Errorf("line %v: repeat 0-%v", s.line, MaxRepeat)
+			}
+			body, end, err := p.block()
+			if err != nil {
+				return nil, "", err
+			}
+			if end != "end" {
+				return nil, "", fmt.Errorf("line %v: repeat without end", s.line)
+			}
+			s.body = body
+
+		case "if":
+			if err := checkCond(s.args); err != nil {
+				return nil, "", fmt.Errorf("line %v: %w", s.line, err)
+			}
+			body, end, err := p.block()
+			if err != nil {
+				return nil, "", err
+			}
+			s.body = body
+			if end == "else" {
+				s.orElse, end, err = p.block()
+				if err != nil {
+					return nil, "", err
+				}
+			}
+			if end != "end" {
+				return nil, "", fmt.Errorf("line %v: if without end", s.line)
+			}
+
+		default:
+			return nil, "", fmt.Errorf("line %v: unknown %q", s.line, s.cmd)
+		}
+		res = append(res, s)
+	}
+	return res, "", nil
+}
+
+func checkCond(args []string) error {
+	if len(args) > 0 && args[0] == "not" {
+		return checkCond(args[1:])
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("if has|random|said ARG")
+	}
+	switch args[0] {
+	case "has", "said":
+		return nil
+	case "random":
+		_, err := strconv.Atoi(args[1])
+		return err
+	}
+	return fmt.Errorf("unknown condition %q", args[0])
+}
+
+// ----------------------------------------
+
+// scriptEnv is the sandbox a script runs in. Scripts can only affect
+// the character who triggered it and the tile it's on.
+type scriptEnv struct {
+	g *Game
+	c *Character
+
+	said string
+
+	ops      int
+	deadline time.Time
+	limits   ScriptLimits
+}
+
+// runScript runs the script for the character, errors are logged.
+func (g *Game) runScript(src Script, c *Character, said string) {
+	if src == "" {
+		return
+	}
+	prog, err := g.compile(src)
+	if err != nil {
+		g.Logf("script: %v", err)
+		return
+	}
+	env := &scriptEnv{
+		g:        g,
+		c:        c,
+		said:     said,
+		deadline: time.Now().Add(g.ScriptLimits.Timeout),
+		limits:   g.ScriptLimits,
+	}
+	if err := env.exec(prog); err != nil && !errors.Is(err, errStop) {
+		g.Logf("script: %v", err)
+	}
+}
+
+// compile returns the compiled script, each source is compiled only
+// once.
+func (g *Game) compile(src Script) ([]stmt, error) {
+	g.scriptsMu.Lock()
+	defer g.scriptsMu.Unlock()
+	if c, found := g.scripts[src]; found {
+		return c.prog, c.err
+	}
+	prog, err := src.Compile()
+	g.scripts[src] = compiled{prog, err}
+	return prog, err
+}
+
+type compiled struct {
+	prog []stmt
+	err  error
+}
+
+func (env *scriptEnv) exec(prog []stmt) error {
+	for _, s := range prog {
+		if err := env.step(s); err != nil {
+			return err
+		}
+		if err := env.do(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// step counts one operation, failing if the limits are exceeded.
+func (env *scriptEnv) step(s stmt) error {
+	env.ops++
+	if env.ops > env.limits.MaxOps {
+		return fmt.Errorf("line %v: exceeded %v operations", s.line, env.limits.MaxOps)
+	}
+	if time.Now().After(env.deadline) {
+		return fmt.Errorf("line %v: exceeded %v", s.line, env.limits.Timeout)
+	}
+	return nil
+}
+
+func (env *scriptEnv) do(s stmt) error {
+	g, c := env.g, env.c
+	switch s.cmd {
+	case "tell":
+		c.Transmit(NewMessage(&EventNotice{Text: env.text(s.args)}))
+
+	case "tellroom":
+		c.TransmitOthers(g, NewMessage(&EventNotice{Text: env.text(s.args)}))
+
+	case "give":
+		c.Inventory.AddItem(Item{Name: Name(s.args[0]), Count: count(s.args)})
+		c.Transmit(NewMessage(&EventInventoryUpdate{Inventory: &c.Inventory}))
+
+	case "take":
+		if err := c.Inventory.RemoveItem(Name(s.args[0]), count(s.args)); err != nil {
+			return errStop
+		}
+		c.Transmit(NewMessage(&EventInventoryUpdate{Inventory: &c.Inventory}))
+
+	case "spawn":
+		g.Items.Add(&Item{
+			Name:     Name(s.args[0]),
+			Count:    count(s.args),
+			Location: c.Location,
+		})
+
+	case "stop":
+		return errStop
+
+	case "repeat":
+		n, _ := strconv.Atoi(s.args[0])
+		for i := 0; i < n; i++ {
+			if i > 0 { // first iteration counted by exec
+				if err := env.step(s); err != nil {
+					return err
+				}
+			}
+			if err := env.exec(s.body); err != nil {
+				return err
+			}
+		}
+
+	case "if":
+		if env.cond(s.args) {
+			return env.exec(s.body)
+		}
+		return env.exec(s.orElse)
+	}
+	return nil
+}
+
+func (env *scriptEnv) cond(args []string) bool {
+	if args[0] == "not" {
+		return !env.cond(args[1:])
+	}
+	switch args[0] {
+	case "has":
+		_, err := env.c.Inventory.Items.FindByName(Name(args[1]))
+		return err == nil
+	case "random":
+		n, _ := strconv.Atoi(args[1])
+		return rand.Intn(100) < n
+	case "said":
+		return strings.Contains(
+			strings.ToLower(env.said), strings.ToLower(args[1]),
+		)
+	}
+	return false
+}
+
+func (env *scriptEnv) text(args []string) string {
+	return strings.NewReplacer(
+		"$name", string(env.c.Name),
+		"$said", env.said,
+	).Replace(strings.Join(args, " "))
+}
+
+func count(args []string) uint {
+	if len(args) < 2 {
+		return 1
+	}
+	n, _ := strconv.Atoi(args[1])
+	if n < 1 {
+		return 1
+	}
+	return uint(n)
+}
+
+var errStop = errors.New("stop")
@@ -0,0 +1,79 @@
+package cible
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScript_Compile(t *testing.T) {
+	ok := []Script{
+		"",
+		"# comment\ntell hello $name",
+		"if has ball\n  give ball 2\nelse\n  spawn ball\nend",
+		"if not said hello\n  stop\nend\nrepeat 3\n  tellroom hi\nend",
+	}
+	for _, src := range ok {
+		if _, err := src.Compile(); err != nil {
+			t.Errorf("%q: %v", src, err)
+		}
+	}
+	bad := []Script{
+		"jump",
+		"give",
+		"give ball many",
+		"if has ball\ntell x",
+		"if maybe ball\nend",
+		"repeat x\nend",
+		"repeat 1000000\nend",
+		"end",
+	}
+	for _, src := range bad {
+		if _, err := src.Compile(); err == nil {
+			t.Errorf("%q: expected error", src)
+		}
+	}
+}
+
+func TestGame_scripts(t *testing.T) {
+	g := NewGame()
+	j := &EventJoinGame{Player: Player{Name: "John"}}
+	g.AffectGame(j)
+	c := j.Character
+
+	g.runScript("if has digipass\n  give ball 2\nend\ntake credit 10", c, "")
+	if c.Inventory.Count("ball") != 2 || c.Inventory.Count(Credit) != 190 {
+		t.Error("script not run", c.Inventory.Items)
+	}
+
+	// take stops the script when missing
+	g.runScript("take cola\nspawn cola", c, "")
+	if _, err := g.Items.At(c.Location).FindByName("cola"); err == nil {
+		t.Error("script not stopped")
+	}
+
+	g.ScriptLimits.MaxOps = 10
+	before := c.Inventory.Count("ball")
+	g.runScript("repeat 100\n  give ball\nend", c, "")
+	if n := c.Inventory.Count("ball") - before; n >= 10 {
+		t.Error("op limit not enforced", n)
+	}
+	before = c.Inventory.Count("ball")
+	g.runScript("repeat 20\nend\ngive ball", c, "") // each iteration counts
+	if c.Inventory.Count("ball") != before {
+		t.Error("op limit not enforced on empty loop")
+	}
+	g.ScriptLimits = ScriptLimits{MaxOps: 1e9, Timeout: time.Millisecond}
+	start := time.Now()
+	g.runScript("repeat 1000\n  repeat 1000\n  end\nend", c, "")
+	if time.Since(start) > time.Second {
+		t.Error("timeout not enforced")
+	}
+
+	g.Characters.Move(c, Location{Area: "a1", Tile: "t4"}) // dispensers
+	g.AffectGame(&EventBuy{Ident: c.Ident, Item: Item{Name: "water"}})
+	use := &EventUse{Ident: c.Ident, Item: Item{Name: "water"}}
+	g.AffectGame(use)
+	if !use.Used || c.Inventory.Count("water") != 0 {
+		t.Error("water not used:", use.Note)
+	}
+}
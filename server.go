@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -29,6 +30,9 @@ type Server struct {
 
 	net.Listener
 
+	// Compress large messages if the client supports it
+	Compress bool
+
 	game *Game
 }
 
@@ -92,13 +96,21 @@ connectLoop:
 }
 
 func (me *Server) communicate(tr *Transceiver) error {
+	client, err := handshake(tr, me.Compress)
+	if err != nil {
+		return err
+	}
 	var cid Ident // set on first EventJoin
 	defer func() {
 		// graceful panic handling
 		if e := recover(); e != nil {
 			me.Log(e)
 		}
-		me.game.Do(&EventDisconnect{cid})
+		// must not be dropped even if the game is busy
+		t := NewTask(&EventDisconnect{cid})
+		if err := me.game.EnqueueContext(context.Background(), t); err == nil {
+			t.Done()
+		}
 		me.Log(cid, " disconnected")
 	}()
 
@@ -112,38 +124,86 @@ func (me *Server) communicate(tr *Transceiver) error {
 		}
 		me.Logf("recv %s", msg.String())
 
-		e, known := NewEvent(msg.EventName)
-		if !known {
-			continue
-		}
-
-		dec := gob.NewDecoder(bytes.NewReader(msg.Body))
-		if err := dec.Decode(e); err != nil {
-			me.Log(err)
-		}
-
-		// new player joined, set the transceiver for further
-		// communication
-		switch e := e.(type) {
-		case *EventJoinGame:
-			e.tr = tr // make sure game can communicate
-		case interface{ SetIdent(string) }:
-			e.SetIdent(string(cid))
+		e, err := clientEvent(msg)
+		if err == nil {
+			// new player joined, set the transceiver for further
+			// communication
+			switch e := e.(type) {
+			case needsTransmitter:
+				e.setTransmitter(client) // make sure game can communicate
+			case Signed:
+				e.Sign(cid)
+			}
+			err = me.game.Do(e)
 		}
-
-		if e, ok := e.(Event); ok {
-			if err := me.game.Do(e); err != nil {
-				msg.Body = []byte(err.Error())
+		if err != nil {
+			me.Logf("%s: %v", msg.String(), err)
+			if err := tr.Transmit(NewErrorMessage(msg.Id, err)); err != nil {
+				return err
 			}
+			continue
 		}
 
 		if e, ok := e.(*EventJoinGame); ok {
 			cid = e.Character.Ident
 		}
-		// ignore other events
 	}
 }
 
+// handshake exchanges hello messages with the client. Returns a
+// transmitter of events the client supports.
+func handshake(tr *Transceiver, compress bool) (*peer, error) {
+	if conn, ok := tr.rw.(net.Conn); ok {
+		conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
+		defer conn.SetReadDeadline(time.Time{})
+	}
+	var msg Message
+	if err := tr.Receive(&msg); err != nil {
+		return nil, err
+	}
+	hello, err := readHello(&msg)
+	if err != nil {
+		tr.Transmit(NewErrorMessage(msg.Id, err))
+		return nil, err
+	}
+	if err := tr.Transmit(NewHelloMessage(compression(compress))); err != nil {
+		return nil, err
+	}
+	negotiate(tr, compress, hello)
+	return &peer{Transceiver: tr, Hello: hello}, nil
+}
+
+// peer drops messages the other side does not support, e.g. new
+// notifications to older clients.
+type peer struct {
+	*Transceiver
+	*Hello
+}
+
+func (me *peer) Transmit(v any) error {
+	if m, ok := v.(Message); ok && !me.Supports(m.EventName) {
+		return nil
+	}
+	return me.Transceiver.Transmit(v)
+}
+
+// clientEvent returns the valid client event in the message. Unknown
+// and server only events are rejected.
+func clientEvent(msg Message) (ClientEvent, error) {
+	v, known := NewEvent(msg.EventName)
+	e, ok := v.(ClientEvent)
+	if !known || !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownEvent, msg.EventName)
+	}
+	dec := gob.NewDecoder(bytes.NewReader(msg.Body))
+	if err := dec.Decode(e); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return e, e.Validate()
+}
+
+var ErrUnknownEvent = errors.New("unknown event")
+
 type needsTransmitter interface {
 	setTransmitter(Transmitter)
 }
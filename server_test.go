@@ -0,0 +1,110 @@
+package cible
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_clientEvent(t *testing.T) {
+	if _, err := clientEvent(NewMessage(&EventMove{Direction: N})); err != nil {
+		t.Error(err)
+	}
+	rejected := []Message{
+		NewMessage(&EventTick{}),
+		NewMessage(&EventStopGame{}),
+		NewMessage(&EventJoin{}), // notification
+		{EventName: "nope"},
+	}
+	for _, m := range rejected {
+		if _, err := clientEvent(m); !errors.Is(err, ErrUnknownEvent) {
+			t.Errorf("%s: %v", m.EventName, err)
+		}
+	}
+	invalid := []Message{
+		NewMessage(&EventMove{Direction: Direction(-1)}),
+		NewMessage(&EventPickup{}),
+		NewMessage(&EventJoinGame{}),
+	}
+	for _, m := range invalid {
+		if _, err := clientEvent(m); err == nil {
+			t.Errorf("%s passed validation", m.EventName)
+		}
+	}
+}
+
+func Test_handshake(t *testing.T) {
+	t.Run("compatible", func(t *testing.T) {
+		client := connectPipe(t, NewHelloMessage(Flate))
+		var m Message
+		if err := client.Receive(&m); err != nil {
+			t.Fatal(err)
+		}
+		hello, err := readHello(&m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hello.Compression != "" {
+			t.Error("compression advertised when not enabled")
+		}
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		hello := NewMessage(&Hello{Version: ProtocolVersion + 1})
+		hello.EventName = "hello"
+		client := connectPipe(t, hello)
+		var m Message
+		if err := client.Receive(&m); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.CheckError(); err == nil || !strings.Contains(err.Error(), "incompatible") {
+			t.Error("expected incompatible version, got", err)
+		}
+	})
+}
+
+func Test_handshake_timeout(t *testing.T) {
+	defer func(v time.Duration) { handshakeTimeout = v }(handshakeTimeout)
+	handshakeTimeout = 10 * time.Millisecond
+
+	srvConn, conn := net.Pipe()
+	defer conn.Close()
+	done := make(chan error, 1)
+	go func() {
+		_, err := handshake(NewTransceiver(srvConn, &GobProtocol{}), false)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected timeout")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("silent client blocks handshake")
+	}
+}
+
+func Test_peer(t *testing.T) {
+	p := &peer{Hello: &Hello{Events: []string{"move"}}}
+	// dropped, ie. not transmitted using the nil transceiver
+	if err := p.Transmit(NewMessage(&EventNotice{})); err != nil {
+		t.Error(err)
+	}
+	if !p.Supports("error") || !p.Supports("move") {
+		t.Error("should support move and error")
+	}
+}
+
+// connectPipe sends the first message to a server side handshake.
+func connectPipe(t *testing.T, first Message) *Transceiver {
+	srvConn, conn := net.Pipe()
+	t.Cleanup(func() { conn.Close(); srvConn.Close() })
+	go handshake(NewTransceiver(srvConn, &GobProtocol{}), false)
+	client := NewTransceiver(conn, &GobProtocol{})
+	if err := client.Transmit(first); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
@@ -0,0 +1,85 @@
+package cible
+
+// startShards starts one event loop per area. Events sent by a
+// character are handled by the loop of the area the character is
+// in, in parallel with other areas. Moves never leave an area as
+// tiles only link within it.
+func (g *Game) startShards() {
+	g.shards = make(map[Ident]chan *Task, len(g.Areas))
+	for _, a := range g.Areas {
+		ch := make(chan *Task, g.MaxTasks)
+		g.shards[a.Ident] = ch
+		g.shardsRunning.Add(1)
+		go g.runShard(ch)
+	}
+}
+
+func (g *Game) runShard(ch chan *Task) {
+	defer g.shardsRunning.Done()
+	for task := range ch {
+		if task.Event == nil { // barrier
+			task.setErr(nil)
+			continue
+		}
+		err := g.AffectGame(task.Event)
+		if err != nil {
+			g.Logf("%T %v", task.Event, err)
+		}
+		task.setErr(err)
+	}
+}
+
+// route sends the task to the loop of the senders area. Returns
+// false if the task must be handled by the main loop.
+func (g *Game) route(task *Task) bool {
+	if g.shards == nil || exclusive(task.Event) {
+		return false
+	}
+	e, ok := task.Event.(interface{ ident() Ident })
+	if !ok {
+		return false
+	}
+	area, found := g.Characters.AreaOf(e.ident())
+	if !found {
+		return false
+	}
+	ch, found := g.shards[area]
+	if !found {
+		return false
+	}
+	ch <- task
+	return true
+}
+
+// barrier blocks until all events routed to area loops are handled,
+// after which the main loop has the game to itself.
+func (g *Game) barrier() {
+	tasks := make([]*Task, 0, len(g.shards))
+	for _, ch := range g.shards {
+		t := NewTask(nil)
+		ch <- t
+		tasks = append(tasks, t)
+	}
+	for _, t := range tasks {
+		t.Done()
+	}
+}
+
+func (g *Game) stopShards() {
+	for _, ch := range g.shards {
+		close(ch)
+	}
+	g.shardsRunning.Wait()
+	g.shards = nil
+}
+
+// exclusive returns true for events affecting more than one area,
+// which are handled by the main loop only.
+func exclusive(e Event) bool {
+	switch e.(type) {
+	case *EventJoinGame, *EventLeave, *EventDisconnect,
+		*EventBroadcast, *EventAttack, *EventTick, *EventStopGame:
+		return true
+	}
+	return false
+}
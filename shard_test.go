@@ -0,0 +1,98 @@
+package cible
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGame_sharded(t *testing.T) {
+	g := newAreasGame(2)
+	g.Sharded = true
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ids := joinAreas(t, g, 2)
+	go g.Run(ctx)
+	time.Sleep(10 * time.Millisecond) // let it start
+
+	for _, id := range ids {
+		m := &EventMove{Ident: id, Direction: N}
+		if err := g.Do(m); err != nil || m.Note != "" {
+			t.Fatal(err, m.Note)
+		}
+		look := &EventLook{Ident: id}
+		g.Do(look)
+		if look.Tile.Ident != "t6" {
+			t.Error("did not move", id, look.Tile.Ident)
+		}
+	}
+	if err := g.Do(&EventDisconnect{Ident: ids[0]}); err != nil {
+		t.Error(err)
+	}
+	if err := g.Do(&EventLook{Ident: ids[0]}); err == nil {
+		t.Error("look after disconnect")
+	}
+	if err := g.Do(&EventStopGame{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkGame_single_loop(b *testing.B) { benchmarkAreas(b, false) }
+func BenchmarkGame_sharded(b *testing.B)     { benchmarkAreas(b, true) }
+
+// benchmarkAreas sends events in parallel to players spread over 8
+// areas.
+func benchmarkAreas(b *testing.B, sharded bool) {
+	g := newAreasGame(8)
+	g.Sharded = sharded
+	g.MaxTasks = 100
+	ids := joinAreas(b, g, 80)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go g.Run(ctx)
+	time.Sleep(10 * time.Millisecond) // let it start
+
+	var n int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		id := ids[int(atomic.AddInt64(&n, 1))%len(ids)]
+		for pb.Next() {
+			g.Do(&EventMove{Ident: id, Direction: N})
+			g.Do(&EventLook{Ident: id})
+			g.Do(&EventMove{Ident: id, Direction: S})
+		}
+	})
+	b.StopTimer()
+	g.Do(&EventStopGame{})
+}
+
+// newAreasGame returns a game with n copies of the spaceport.
+func newAreasGame(n int) *Game {
+	g := NewGame()
+	for i := 2; i <= n; i++ {
+		a := Spaceport()
+		a.Ident = Ident(fmt.Sprintf("a%d", i))
+		g.Areas = append(g.Areas, a)
+	}
+	return g
+}
+
+// joinAreas joins n players spread over all areas, before the game
+// runs.
+func joinAreas(t testing.TB, g *Game, n int) []Ident {
+	ids := make([]Ident, n)
+	for i := range ids {
+		j := &EventJoinGame{Player: Player{Name: Name(fmt.Sprintf("John%v", i))}}
+		if err := g.AffectGame(j); err != nil {
+			t.Fatal(err)
+		}
+		c := j.Character
+		c.Energy = 1 << 30 // never tired
+		a := g.Areas[i%len(g.Areas)]
+		g.Characters.Move(c, Location{Area: a.Ident, Tile: "t1"})
+		ids[i] = c.Ident
+	}
+	return ids
+}
@@ -1,5 +1,7 @@
 package cible
 
+import "time"
+
 func Spaceport() *Area {
 	t1 := &Tile{
 		Short: "Center Stateroom",
@@ -7,7 +9,8 @@ func Spaceport() *Area {
 		Long: `
 
 A large tree with pinkish fruits grows in the center. Surrounded by
-benches with soft padding. The large stateroom is bright and the
+benches with soft padding. {{if .Night}}The large stateroom is dimmed
+for the night and the{{else}}The large stateroom is bright and the{{end}}
 ceiling transparently shows the galaxy augmented with names of nearest
 starsystems. Alpha Centauri, Barnard's Star and Luhman 16 all sparkle
 in bright colors.
@@ -38,16 +41,49 @@ enhancment machine for small items.
 	t4 := &Tile{
 		Short: "West Stateroom",
 		Long:  `Couple of drink and food dispensers are humming.`,
+		Ambient: &Ambient{
+			Messages: []string{
+				"A dispenser rattles and goes quiet.",
+				"The smell of noodles drifts from the dispensers.",
+			},
+			Every: 2 * time.Minute,
+		},
+		Vendors: Vendors{
+			{
+				Short: "drink dispenser",
+				Offers: Offers{
+					{Name: "water", Price: 2, Stock: 20, Max: 20},
+					{Name: "cola", Price: 5, Stock: 10, Max: 10},
+				},
+				RestockEvery: time.Minute,
+			},
+			{
+				Short: "food dispenser",
+				Offers: Offers{
+					{Name: "noodles", Price: 12, Stock: 5, Max: 5},
+					{Name: "sandwich", Price: 8, Stock: 5, Max: 5},
+				},
+				RestockEvery: 2 * time.Minute,
+			},
+		},
 	}
 
 	t5 := &Tile{
 		Short: "Sitting room",
-		Long:  `A lounge with some tables and chairs.`,
+		Long: `A lounge with some tables and chairs.
+{{- if .Occupants}} {{join .Occupants ", "}} rest here.{{end}}`,
 	}
 
 	t6 := &Tile{
 		Short: "News room",
 		Long:  `On the north wall news are displayed on a multi screen setup.`,
+		Ambient: &Ambient{
+			Messages: []string{
+				"The news screens flicker as a new headline rolls in.",
+			},
+			Every: 3 * time.Minute,
+		},
+		NewsBoard: NewNewsBoard(10),
 	}
 
 	t7 := &Tile{
@@ -58,6 +94,12 @@ enhancment machine for small items.
 	t8 := &Tile{
 		Short: "Rest room",
 		Long:  `Multiple toilets are available, some are occupied or just broken`,
+		Scripts: Scripts{
+			OnEnter: `
+if random 30
+  tell Someone flushes in one of the stalls.
+end`,
+		},
 	}
 
 	t9 := &Tile{
@@ -68,6 +110,11 @@ enhancment machine for small items.
 	area := &Area{
 		Ident: "a1",
 		Title: "Spaceport",
+		Reset: ResetPolicy{
+			Every: 6 * time.Hour,
+			Doors: true,
+			Items: true,
+		},
 	}
 	area.AddTile(t1, t2, t3, t4, t5, t6, t7, t8, t9)
 
@@ -87,7 +134,246 @@ enhancment machine for small items.
 	t4.Link(t5, N)
 	t5.Link(t6, E)
 	t6.Link(t7, E)
-	t7.Link(t8, S)
+	t7.LinkDoor(t8, S, &Door{
+		Short:  "rest room door",
+		Closed: true,
+		Locked: true,
+		Key:    "digipass",
+	})
 	t8.Link(t9, S)
 	return area
 }
+
+// SpaceportSpawns returns where items are found in the spaceport.
+func SpaceportSpawns() Spawns {
+	return Spawns{
+		{
+			Name:     "ball",
+			Location: Location{Area: "a1", Tile: "t9"},
+			Count:    1,
+			Every:    10 * time.Minute,
+		},
+		{
+			Name:     "gloves",
+			Location: Location{Area: "a1", Tile: "t3"},
+			Count:    1,
+			Every:    30 * time.Minute,
+		},
+		{
+			Name:     "bag",
+			Location: Location{Area: "a1", Tile: "t5"},
+			Count:    1,
+			Every:    30 * time.Minute,
+		},
+		{
+			Name:     "locker",
+			Location: Location{Area: "a1", Tile: "t8"},
+			Count:    1,
+		},
+		{
+			Name:     "crate",
+			Location: Location{Area: "a1", Tile: "t3"},
+			Count:    1,
+		},
+		{
+			Name:     "cybromat",
+			Location: Location{Area: "a1", Tile: "t3"},
+			Count:    1,
+		},
+		{
+			Name:     Credit,
+			Location: Location{Area: "a1", Tile: "t5"},
+			Count:    5,
+			Every:    5 * time.Minute,
+		},
+	}
+}
+
+// SpaceportNPCs returns the non player characters of the spaceport.
+func SpaceportNPCs() NPCs {
+	drone := DefaultStats()
+	drone.Health, drone.MaxHealth = 30, 30
+	drone.Strength = 2
+	return NPCs{
+		{
+			Name:     "drone",
+			Location: Location{Area: "a1", Tile: "t7"},
+			Stats:    drone,
+			Loot: Items{
+				{Name: Credit, Count: 10},
+			},
+			Hostile:      true,
+			RespawnAfter: 2 * time.Minute,
+		},
+		{
+			Name:     "janitor",
+			Location: Location{Area: "a1", Tile: "t6"},
+			Stats:    DefaultStats(),
+			Says: `Welcome traveller! Have you tried the Cybromat in the
+tech room? It does wonders, they say.`,
+			Scripts: Scripts{
+				OnSay: `
+if said ball
+  tell The janitor mumbles: kids keep leaving balls in the south-east stateroom.
+end`,
+			},
+		},
+	}
+}
+
+// SpaceportQuests returns quests available in the spaceport.
+func SpaceportQuests() Quests {
+	return Quests{
+		{
+			Ident: "q1",
+			Title: "Getting around",
+			Objectives: []Objective{
+				{
+					Short:         "visit the news room",
+					ObjectiveKind: Visit,
+					Location:      Location{Area: "a1", Tile: "t6"},
+				},
+				{
+					Short:         "talk to the janitor",
+					ObjectiveKind: Talk,
+					Target:        "janitor",
+				},
+				{
+					Short:         "use the cybromat in the tech room",
+					ObjectiveKind: Use,
+					Target:        "cybromat",
+				},
+			},
+			Reward: Items{
+				{Name: Credit, Count: 50},
+			},
+			Experience: 50,
+		},
+		{
+			Ident: "q2",
+			Title: "Lost and found",
+			Objectives: []Objective{
+				{
+					Short:         "find the ball someone lost",
+					ObjectiveKind: Pickup,
+					Target:        "ball",
+				},
+			},
+			Reward: Items{
+				{Name: Credit, Count: 20},
+			},
+			Experience: 20,
+		},
+	}
+}
+
+// SpaceportItems returns the catalog of items found in the spaceport.
+func SpaceportItems() Catalog {
+	c := make(Catalog)
+	c.Add(
+		&ItemDef{
+			Name:        "credit",
+			Description: "The currency used throughout the galaxy.",
+			Value:       1,
+			Stackable:   true,
+			Tradeable:   true,
+		},
+		&ItemDef{
+			Name: "communicator",
+			Description: `A wrist worn device for talking with people far
+away.`,
+			Weight:    120,
+			Value:     150,
+			Usable:    true,
+			Tradeable: true,
+			Effect:    "tune",
+		},
+		&ItemDef{
+			Name: "digipass",
+			Description: `Your personal identification, grants access to
+restricted areas.`,
+			Weight: 10,
+			Usable: true,
+			Effect: "unlock",
+		},
+		&ItemDef{
+			Name:        "ball",
+			Description: "A small bouncy ball, slightly worn.",
+			Weight:      60,
+			Value:       5,
+			Tradeable:   true,
+		},
+		&ItemDef{
+			Name:        "water",
+			Description: "A bottle of recycled water, tastes of metal.",
+			Weight:      500,
+			Value:       2,
+			Tradeable:   true,
+			Scripts: Scripts{
+				OnUse: `
+take water
+tell You drink the water, it tastes of metal.
+tellroom $name drinks some water.`,
+			},
+		},
+		&ItemDef{
+			Name:        "cola",
+			Description: "A can of fizzy Centauri cola.",
+			Weight:      330,
+			Value:       5,
+			Tradeable:   true,
+		},
+		&ItemDef{
+			Name:        "noodles",
+			Description: "Self heating noodles in a paper cup.",
+			Weight:      250,
+			Value:       12,
+			Tradeable:   true,
+		},
+		&ItemDef{
+			Name:        "sandwich",
+			Description: "Algae bread with something that looks like cheese.",
+			Weight:      200,
+			Value:       8,
+			Tradeable:   true,
+		},
+		&ItemDef{
+			Name:        "bag",
+			Description: "A canvas shoulder bag with a broken zipper.",
+			Weight:      300,
+			Value:       15,
+			Tradeable:   true,
+			Capacity:    5,
+		},
+		&ItemDef{
+			Name:        "locker",
+			Description: "A dented metal locker bolted to the wall.",
+			Capacity:    10,
+			Fixed:       true,
+		},
+		&ItemDef{
+			Name:        "crate",
+			Description: "A heavy plastic crate for spare parts.",
+			Capacity:    20,
+			Fixed:       true,
+		},
+		&ItemDef{
+			Name:        "cybromat",
+			Description: "The Cybromat 100, an automated cyborg enhancement station.",
+			Fixed:       true,
+			Scripts: Scripts{
+				OnUse: "tell the cybromat scans you, insert an item to enhance it",
+			},
+		},
+		&ItemDef{
+			Name:        "gloves",
+			Description: "Padded work gloves with a firm grip.",
+			Weight:      150,
+			Value:       20,
+			Tradeable:   true,
+			Slot:        Hands,
+			Bonus:       Stats{Strength: 1, Defense: 1},
+		},
+	)
+	return c
+}
@@ -0,0 +1,69 @@
+package cible
+
+import "time"
+
+// Spawn keeps a location supplied with an item. Missing items are
+// put back one at the time, every interval.
+type Spawn struct {
+	Name // of item
+	Location
+	Count uint // max at location
+	Every time.Duration
+
+	last time.Time
+}
+
+// Due returns true if it's time to spawn.
+func (me *Spawn) Due(now time.Time) bool {
+	if me.last.IsZero() {
+		me.last = now
+		return false
+	}
+	if me.Every <= 0 || now.Sub(me.last) < me.Every {
+		return false
+	}
+	me.last = now
+	return true
+}
+
+type Spawns []*Spawn
+
+// ResetPolicy defines how an area is restored to its initial state.
+type ResetPolicy struct {
+	Every time.Duration // zero means never
+
+	Doors   bool // close and lock doors as they were
+	Items   bool // remove loose items and fill all spawns, see resetArea
+	Vendors bool // restock all vendors fully
+}
+
+// resetDue returns true if it's time to reset the area.
+func (me *Area) resetDue(now time.Time) bool {
+	if me.lastReset.IsZero() {
+		me.lastReset = now
+		me.saveDoors()
+		return false
+	}
+	if me.Reset.Every <= 0 || now.Sub(me.lastReset) < me.Reset.Every {
+		return false
+	}
+	me.lastReset = now
+	return true
+}
+
+// saveDoors remembers the door states so they can be restored on
+// reset.
+func (me *Area) saveDoors() {
+	me.initialDoors = make(map[*Door]Door)
+	for _, t := range me.Tiles {
+		for _, d := range t.Doors {
+			me.initialDoors[d] = *d
+		}
+	}
+}
+
+func (me *Area) restoreDoors() {
+	for d, v := range me.initialDoors {
+		*d = v
+	}
+}
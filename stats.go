@@ -0,0 +1,139 @@
+package cible
+
+import "time"
+
+// DefaultStats returns the stats of a new character.
+func DefaultStats() Stats {
+	return Stats{
+		Health:    100,
+		MaxHealth: 100,
+		Energy:    100,
+		MaxEnergy: 100,
+		Strength:  1,
+		Level:     1,
+	}
+}
+
+// Stats of a character, also used as bonus given by equipped items.
+type Stats struct {
+	Health, MaxHealth int
+	Energy, MaxEnergy int
+
+	Strength int
+	Defense  int
+	Skills
+
+	Experience int
+	Level      int
+}
+
+// Add returns the sum of both stats, except experience and level.
+func (s Stats) Add(v Stats) Stats {
+	s.Health += v.Health
+	s.MaxHealth += v.MaxHealth
+	s.Energy += v.Energy
+	s.MaxEnergy += v.MaxEnergy
+	s.Strength += v.Strength
+	s.Defense += v.Defense
+	if len(v.Skills) > 0 {
+		skills := make(Skills, len(s.Skills)+len(v.Skills))
+		for k, n := range s.Skills {
+			skills[k] = n
+		}
+		for k, n := range v.Skills {
+			skills[k] += n
+		}
+		s.Skills = skills
+	}
+	return s
+}
+
+// Gain adds experience and returns true if a new level was reached.
+func (s *Stats) Gain(xp int) bool {
+	s.Experience += xp
+	var up bool
+	for s.Experience >= s.NextLevel() {
+		s.Level++
+		s.MaxHealth += 10
+		s.MaxEnergy += 10
+		up = true
+	}
+	return up
+}
+
+// NextLevel returns the experience needed for the next level.
+func (s *Stats) NextLevel() int {
+	// 100, 300, 600, 1000, ...
+	return 50 * s.Level * (s.Level + 1)
+}
+
+// Regenerate restores some health and energy, up to max.
+func (s *Stats) Regenerate(health, energy int) {
+	s.Health += health
+	if s.Health > s.MaxHealth {
+		s.Health = s.MaxHealth
+	}
+	s.Energy += energy
+	if s.Energy > s.MaxEnergy {
+		s.Energy = s.MaxEnergy
+	}
+}
+
+// Regen counts points regenerated over time, e.g. one health every
+// five seconds.
+type Regen struct {
+	Every time.Duration
+
+	last time.Time
+}
+
+// Points returns the number of points regenerated since last call.
+func (me *Regen) Points(now time.Time) int {
+	if me.last.IsZero() || now.Before(me.last) {
+		me.last = now
+		return 0
+	}
+	n := now.Sub(me.last) / me.Every
+	me.last = me.last.Add(n * me.Every)
+	return int(n)
+}
+
+// Skills by name, e.g. hacking
+type Skills map[Name]int
+
+// Modifier changes the effective stats of a character, e.g. equipped
+// items or cybernetic enhancements.
+type Modifier interface {
+	Modify(g *Game, c *Character, s *Stats)
+}
+
+// ModifierFunc makes a func a Modifier
+type ModifierFunc func(g *Game, c *Character, s *Stats)
+
+func (fn ModifierFunc) Modify(g *Game, c *Character, s *Stats) { fn(g, c, s) }
+
+// DefaultModifiers returns the modifiers used by NewGame.
+func DefaultModifiers() []Modifier {
+	return []Modifier{
+		ModifierFunc(equipmentBonus),
+	}
+}
+
+// equipmentBonus adds bonus of equipped items
+func equipmentBonus(g *Game, c *Character, s *Stats) {
+	for _, item := range c.Inventory.Items {
+		if item.Equipped {
+			*s = s.Add(g.Catalog.Def(item.Name).Bonus)
+		}
+	}
+}
+
+// Slot where an item is equipped
+type Slot string
+
+const (
+	Head  Slot = "head"
+	Body  Slot = "body"
+	Hands Slot = "hands"
+	Feet  Slot = "feet"
+)
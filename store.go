@@ -0,0 +1,80 @@
+package cible
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// CharacterStore persists characters between games, by name.
+type CharacterStore interface {
+	Load(Name) (*Character, error)
+	Save(*Character) error
+}
+
+// NewDirStore returns a store keeping each character as a json file
+// in the given directory, which is created if missing.
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+type DirStore struct {
+	dir string
+}
+
+// Load returns ErrNotStored if the character has never been saved.
+func (me *DirStore) Load(n Name) (*Character, error) {
+	filename, err := me.filename(n)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotStored
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Character
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("load %s: %w", n, err)
+	}
+	return &c, nil
+}
+
+func (me *DirStore) Save(c *Character) error {
+	filename, err := me.filename(c.Name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write complete file before replacing the old one
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+func (me *DirStore) filename(n Name) (string, error) {
+	if !validName.MatchString(string(n)) {
+		return "", fmt.Errorf("cannot store character named %q", n)
+	}
+	return filepath.Join(me.dir, string(n)+".json"), nil
+}
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,64}$`)
+
+var (
+	ErrNotStored = errors.New("character not stored")
+	ErrNameTaken = errors.New("name already in game")
+)
@@ -1,20 +1,68 @@
 package cible
 
-import "fmt"
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"text/template"
+	"time"
+)
 
 type Tile struct {
 	Ident
 	Short
 	Long
 	Nav
+	Doors
 
 	*Cybromat
+	Vendors
+	Scripts
+	*Ambient
+	*NewsBoard
 }
 
 func (t *Tile) String() string {
 	return fmt.Sprintf("%s %s", t.Ident, t.Short)
 }
 
+// View returns a copy of what players may see of the tile, i.e. no
+// scripts or internal state. It's safe to send while the game
+// changes the tile.
+func (me *Tile) View() *Tile {
+	v := &Tile{
+		Ident: me.Ident,
+		Short: me.Short,
+		Long:  me.Long,
+		Nav:   me.Nav,
+	}
+	if len(me.Doors) > 0 {
+		v.Doors = make(Doors, len(me.Doors))
+		for d, door := range me.Doors {
+			v.Doors[d] = &Door{
+				Short:  door.Short,
+				Closed: door.Closed,
+				Locked: door.Locked,
+			}
+		}
+	}
+	for _, vendor := range me.Vendors {
+		offers := make(Offers, len(vendor.Offers))
+		for i, o := range vendor.Offers {
+			cp := *o
+			offers[i] = &cp
+		}
+		v.Vendors = append(v.Vendors, &Vendor{Short: vendor.Short, Offers: offers})
+	}
+	if b := me.NewsBoard; b != nil {
+		v.NewsBoard = &NewsBoard{
+			Headlines: append([]Headline(nil), b.Headlines...),
+		}
+	}
+	return v
+}
+
 // Link creates a dual link between a tile and the given ones
 func (me *Tile) Link(to ...interface{}) {
 	for i := 0; i < len(to); i += 2 {
@@ -36,3 +84,87 @@ func (me *Tile) Link(to ...interface{}) {
 		t.Nav[opposite[d]] = me.Ident
 	}
 }
+
+// LinkDoor links the tiles in both directions, like Link, with the
+// given door in between.
+func (me *Tile) LinkDoor(t *Tile, d Direction, door *Door) {
+	me.Link(t, d)
+	if me.Doors == nil {
+		me.Doors = make(Doors)
+	}
+	if t.Doors == nil {
+		t.Doors = make(Doors)
+	}
+	me.Doors[d] = door
+	t.Doors[opposite[d]] = door
+}
+
+// Describe returns the long description rendered as a text/template
+// with the given state, e.g.
+//
+//	The ceiling is {{if .Night}}dark{{else}}bright{{end}}.
+func (me *Tile) Describe(state TileState) (Long, error) {
+	tpl, err := template.New(string(me.Ident)).Funcs(tileFuncs).Parse(string(me.Long))
+	if err != nil {
+		return me.Long, err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, state); err != nil {
+		return me.Long, err
+	}
+	return Long(buf.String()), nil
+}
+
+// TileState is what tile descriptions can depend on.
+type TileState struct {
+	Now       time.Time
+	Night     bool
+	Occupants []Name // other characters
+	Items     []Name // loose items
+}
+
+var tileFuncs = template.FuncMap{
+	"join": func(v []Name, sep string) string {
+		s := make([]string, len(v))
+		for i, n := range v {
+			s[i] = string(n)
+		}
+		return strings.Join(s, sep)
+	},
+}
+
+// Ambient messages are sent to characters on a tile, one at random
+// every interval.
+type Ambient struct {
+	Messages []string
+	Every    time.Duration
+
+	last time.Time
+}
+
+// Due returns true if it's time for the next message.
+func (me *Ambient) Due(now time.Time) bool {
+	if me.last.IsZero() {
+		me.last = now
+		return false
+	}
+	if me.Every <= 0 || now.Sub(me.last) < me.Every {
+		return false
+	}
+	me.last = now
+	return true
+}
+
+// Message returns a random message, empty if there are none.
+func (me *Ambient) Message() string {
+	if len(me.Messages) == 0 {
+		return ""
+	}
+	return me.Messages[rand.Intn(len(me.Messages))]
+}
+
+// isNight returns true between 20:00 and 06:00.
+func isNight(now time.Time) bool {
+	h := now.Hour()
+	return h >= 20 || h < 6
+}
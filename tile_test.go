@@ -3,6 +3,7 @@ package cible
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 func ExampleTile_Link() {
@@ -28,3 +29,63 @@ func TestTile_Link(t *testing.T) {
 	t1.Link(t2, N) // first is ok
 	t1.Link(t3, N) // but you should not be able to override it
 }
+
+func TestTile_View(t *testing.T) {
+	tile := &Tile{
+		Ident:    "t1",
+		Scripts:  Scripts{OnEnter: "tell hi"},
+		Ambient:  &Ambient{Messages: []string{"hum"}},
+		Cybromat: NewCybromat(),
+		Vendors: Vendors{
+			{Short: "dispenser", Offers: Offers{{Name: "water", Stock: 1}}},
+		},
+	}
+	v := tile.View()
+	if v.Scripts != nil || v.Ambient != nil || v.Cybromat != nil {
+		t.Error("internals in view", v)
+	}
+	tile.Vendors[0].Offers[0].Stock = 0
+	if v.Vendors[0].Offers[0].Stock != 1 {
+		t.Error("view shares offers with tile")
+	}
+}
+
+func TestTile_Describe(t *testing.T) {
+	tile := &Tile{
+		Ident: "t1",
+		Long: `It is {{if .Night}}dark{{else}}light{{end}}.
+{{- if .Occupants}} {{join .Occupants ", "}} are here.{{end}}
+{{- range .Items}} A {{.}} lies on the floor.{{end}}`,
+	}
+	got, err := tile.Describe(TileState{
+		Night:     true,
+		Occupants: []Name{"John", "Eve"},
+		Items:     []Name{"ball"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "It is dark. John, Eve are here. A ball lies on the floor."
+	if string(got) != exp {
+		t.Errorf("\ngot: %s\nexp: %s", got, exp)
+	}
+
+	tile.Long = "{{if}"
+	if got, err := tile.Describe(TileState{}); err == nil || got != tile.Long {
+		t.Error("expected error and unrendered description", got)
+	}
+}
+
+func TestAmbient_Due(t *testing.T) {
+	a := &Ambient{Messages: []string{"hum"}, Every: time.Minute}
+	now := time.Now()
+	if a.Due(now) {
+		t.Error("due on first check")
+	}
+	if a.Due(now.Add(time.Second)) {
+		t.Error("due before interval")
+	}
+	if !a.Due(now.Add(time.Minute)) || a.Message() != "hum" {
+		t.Error("not due after interval")
+	}
+}
@@ -1,27 +1,78 @@
 package cible
 
 import (
+	"fmt"
 	"io"
+	"sync"
 )
 
 func NewTransceiver(rw io.ReadWriter, proto Protocol) *Transceiver {
 	return &Transceiver{
-		proto.NewEncoder(rw),
-		proto.NewDecoder(rw),
+		Encoder: proto.NewEncoder(rw),
+		Decoder: proto.NewDecoder(rw),
+		rw:      rw,
 	}
 }
 
 type Transceiver struct {
 	Encoder
 	Decoder
+
+	// CompressAbove compresses message bodies larger than this many
+	// bytes, zero disables. Set if both sides support compression,
+	// see Hello.
+	CompressAbove int
+
+	mu sync.Mutex // one message at the time
+	rw io.ReadWriter
 }
 
+// Transmit is safe for concurrent use.
 func (me *Transceiver) Transmit(v any) error {
+	if m, ok := v.(Message); ok && me.CompressAbove > 0 &&
+		len(m.Body) > me.CompressAbove && !m.Compressed {
+		var body []byte
+		var err error
+		if m.packed != nil {
+			body, err = m.packed.compress(m.Body)
+		} else {
+			body, err = compress(m.Body)
+		}
+		if err != nil {
+			return err
+		}
+		if len(body) < len(m.Body) {
+			m.Body, m.Compressed = body, true
+			v = m
+		}
+	}
+	me.mu.Lock()
+	defer me.mu.Unlock()
 	return me.Encode(v)
 }
 
+// Receive decodes the next message, decompressing its body if
+// needed.
 func (me *Transceiver) Receive(v any) error {
-	return me.Decode(v)
+	if err := me.Decode(v); err != nil {
+		return err
+	}
+	if m, ok := v.(*Message); ok && m.Compressed {
+		body, err := decompress(m.Body)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.EventName, err)
+		}
+		m.Body, m.Compressed = body, false
+	}
+	return nil
+}
+
+// Close closes the underlying connection, if it can be closed.
+func (me *Transceiver) Close() error {
+	if c, ok := me.rw.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
 }
 
 type Transmitter interface {
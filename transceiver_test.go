@@ -0,0 +1,66 @@
+package cible
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTransceiver_compression(t *testing.T) {
+	body := bytes.Repeat([]byte("long tile description "), 100)
+	send := func(compressAbove int, body []byte) (int, Message) {
+		var buf bytes.Buffer
+		tr := NewTransceiver(&buf, &GobProtocol{})
+		tr.CompressAbove = compressAbove
+		if err := tr.Transmit(Message{EventName: "look", Body: body}); err != nil {
+			t.Fatal(err)
+		}
+		size := buf.Len()
+		var m Message
+		if err := tr.Receive(&m); err != nil {
+			t.Fatal(err)
+		}
+		return size, m
+	}
+
+	plain, _ := send(0, body)
+	compressed, m := send(DefaultCompressAbove, body)
+	if compressed >= plain {
+		t.Errorf("not compressed, %v >= %v bytes", compressed, plain)
+	}
+	if !bytes.Equal(m.Body, body) || m.Compressed {
+		t.Error("body not restored")
+	}
+
+	small := []byte("short")
+	if _, m := send(DefaultCompressAbove, small); !bytes.Equal(m.Body, small) {
+		t.Error("small body changed", m.Body)
+	}
+}
+
+func TestTransceiver_compressOnce(t *testing.T) {
+	m := NewMessage(&EventNotice{Text: strings.Repeat("hum ", DefaultCompressAbove)})
+	var bodies [][]byte
+	for i := 0; i < 2; i++ { // e.g. two players on the same tile
+		var buf bytes.Buffer
+		tr := NewTransceiver(&buf, &GobProtocol{})
+		tr.CompressAbove = DefaultCompressAbove
+		if err := tr.Transmit(m); err != nil {
+			t.Fatal(err)
+		}
+		bodies = append(bodies, m.packed.body)
+	}
+	if bodies[0] == nil || &bodies[0][0] != &bodies[1][0] {
+		t.Error("compressed more than once")
+	}
+	if m.Compressed {
+		t.Error("original message changed")
+	}
+}
+
+func Test_decompress(t *testing.T) {
+	big, _ := compress(make([]byte, maxBodySize+1))
+	if _, err := decompress(big); err == nil {
+		t.Error("expected error for too large body")
+	}
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"bytes"
+
+	. "github.com/gregoryv/cible"
+)
+
+// RenderMap draws the area map as ascii. The tile you are on is
+// marked with @, tiles not yet visited with ?. Radius limits the
+// map to tiles around you, 0 means all tiles.
+func RenderMap(m *AreaMap, here Ident, visited func(Ident) bool, radius int) []byte {
+	var center Position
+	for _, t := range m.Tiles {
+		if t.Ident == here {
+			center = t.Position
+		}
+	}
+	tiles := make([]MapTile, 0, len(m.Tiles))
+	for _, t := range m.Tiles {
+		if radius > 0 && (abs(t.X-center.X) > radius || abs(t.Y-center.Y) > radius) {
+			continue
+		}
+		tiles = append(tiles, t)
+	}
+	if len(tiles) == 0 {
+		return nil
+	}
+
+	min, max := tiles[0].Position, tiles[0].Position
+	for _, t := range tiles {
+		if t.X < min.X {
+			min.X = t.X
+		}
+		if t.Y < min.Y {
+			min.Y = t.Y
+		}
+		if t.X > max.X {
+			max.X = t.X
+		}
+		if t.Y > max.Y {
+			max.Y = t.Y
+		}
+	}
+	width := (max.X-min.X)*cellStride + cellWidth
+	height := (max.Y-min.Y)*2 + 1
+	canvas := make([][]byte, height)
+	for i := range canvas {
+		canvas[i] = bytes.Repeat([]byte(" "), width)
+	}
+	put := func(row, col int, c byte) {
+		if row < 0 || row >= height || col < 0 || col >= width {
+			return
+		}
+		switch canvas[row][col] {
+		case '/', '\\':
+			if canvas[row][col] != c {
+				c = 'X' // crossing diagonals
+			}
+		}
+		canvas[row][col] = c
+	}
+
+	for _, t := range tiles {
+		row := (t.Y - min.Y) * 2
+		col := (t.X - min.X) * cellStride
+		cell := "[ ]"
+		switch {
+		case t.Ident == here:
+			cell = "[@]"
+		case visited != nil && !visited(t.Ident):
+			cell = "[?]"
+		}
+		copy(canvas[row][col:], cell)
+
+		for d, id := range t.Nav {
+			if id == "" {
+				continue
+			}
+			var (
+				r, c int
+				link byte
+			)
+			switch Direction(d) {
+			case N:
+				r, c, link = row-1, col+1, '|'
+			case NE:
+				r, c, link = row-1, col+3, '/'
+			case E:
+				r, c, link = row, col+3, '-'
+			case SE:
+				r, c, link = row+1, col+3, '\\'
+			case S:
+				r, c, link = row+1, col+1, '|'
+			case SW:
+				r, c, link = row+1, col-1, '/'
+			case W:
+				r, c, link = row, col-1, '-'
+			case NW:
+				r, c, link = row-1, col-1, '\\'
+			}
+			if door := t.Doors.Door(Direction(d)); door != nil && door.Passable() != nil {
+				link = '#'
+			}
+			put(r, c, link)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, line := range canvas {
+		buf.Write(bytes.TrimRight(line, " "))
+		buf.WriteString("\n")
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+const (
+	cellWidth  = 3 // [ ]
+	cellStride = cellWidth + 1
+)
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
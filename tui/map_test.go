@@ -0,0 +1,27 @@
+package tui
+
+import (
+	"testing"
+
+	. "github.com/gregoryv/cible"
+)
+
+func TestRenderMap(t *testing.T) {
+	m := NewAreaMap(Spaceport())
+	got := string(RenderMap(m, "t1", nil, 0))
+	exp := `[ ]-[ ]-[ ]
+ | \ |   #
+[ ]-[@] [ ]
+ | / |   |
+[ ]-[ ]-[ ]`
+	if got != exp {
+		t.Errorf("got\n%s\nexpected\n%s", got, exp)
+	}
+
+	visited := func(id Ident) bool { return id == "t1" }
+	got = string(RenderMap(m, "t6", visited, 1))
+	if got == "" {
+		t.Error("empty minimap")
+	}
+	t.Log("\n" + got)
+}
@@ -34,6 +34,8 @@ func NewUI() *UI {
 		out: make(chan Message, 1),
 		in:  make(chan Message, 1),
 
+		visited: make(map[Location]bool),
+
 		cols: cols,
 		rows: rows,
 	}
@@ -51,6 +53,10 @@ type UI struct {
 	Character
 	Location string // used in prompt
 
+	visited map[Location]bool
+	areaMap *AreaMap // last received
+	miniMap bool     // show map around you when moving
+
 	cols, rows int
 }
 
@@ -114,6 +120,10 @@ eventLoop:
 
 		case m := <-u.in:
 			// handle incoming messages
+			if err := m.CheckError(); err != nil {
+				u.Println(err)
+				continue
+			}
 			e, known := NewEvent(m.EventName)
 			if !known {
 				continue
@@ -130,6 +140,21 @@ eventLoop:
 			case "l", "look":
 				send <- NewMessage(&EventLook{})
 
+			case "st", "status":
+				send <- NewMessage(&EventStatus{})
+
+			case "quests":
+				send <- NewMessage(&EventQuests{})
+
+			case "m", "map":
+				send <- NewMessage(&EventMap{})
+
+			case "minimap":
+				u.miniMap = !u.miniMap
+				if u.miniMap && u.areaMap == nil {
+					send <- NewMessage(&EventMap{})
+				}
+
 			case "i", "inventory":
 				u.showInventory()
 
@@ -156,6 +181,105 @@ eventLoop:
 						},
 					})
 
+				case "u", "use", "equip", "unequip":
+					if len(fields) == 1 {
+						u.Printf("%s what?\n", fields[0])
+						continue eventLoop
+					}
+					item := Item{Name: Name(fields[1])}
+					switch fields[0] {
+					case "equip":
+						send <- NewMessage(&EventEquip{Item: item})
+					case "unequip":
+						send <- NewMessage(&EventUnequip{Item: item})
+					default:
+						send <- NewMessage(&EventUse{Item: item})
+					}
+
+				case "buy", "sell":
+					if len(fields) == 1 {
+						u.Printf("%s what?\n", fields[0])
+						continue eventLoop
+					}
+					item := Item{Name: Name(fields[1])}
+					if fields[0] == "buy" {
+						send <- NewMessage(&EventBuy{Item: item})
+					} else {
+						send <- NewMessage(&EventSell{Item: item})
+					}
+
+				case "put", "take":
+					// put ITEM in CONTAINER, take ITEM from CONTAINER
+					if len(fields) < 3 {
+						u.Printf("%s what %s what?\n", fields[0], map[string]string{
+							"put": "in", "take": "from",
+						}[fields[0]])
+						continue eventLoop
+					}
+					item := Item{Name: Name(fields[1])}
+					box := Name(fields[len(fields)-1])
+					if fields[0] == "put" {
+						send <- NewMessage(&EventPut{Item: item, Container: box})
+					} else {
+						send <- NewMessage(&EventTake{Item: item, Container: box})
+					}
+
+				case "read":
+					if len(fields) == 1 || fields[1] != "news" {
+						u.Println("read what?")
+						continue eventLoop
+					}
+					send <- NewMessage(&EventReadNews{})
+
+				case "post":
+					if len(fields) == 1 {
+						u.Println("post what?")
+						continue eventLoop
+					}
+					send <- NewMessage(&EventPostNews{
+						Text: strings.Join(fields[1:], " "),
+					})
+
+				case "attack":
+					if len(fields) == 1 {
+						u.Println("attack who?")
+						continue eventLoop
+					}
+					send <- NewMessage(&EventAttack{Target: Name(fields[1])})
+
+				case "c", "channel":
+					if len(fields) == 1 {
+						u.Println("say what on the channel?")
+						continue eventLoop
+					}
+					send <- NewMessage(&EventBroadcast{
+						Text: strings.Join(fields[1:], " "),
+					})
+
+				case "g", "goto":
+					if len(fields) == 1 {
+						u.Println("goto where?")
+						continue eventLoop
+					}
+					send <- NewMessage(&EventGoto{
+						Destination: strings.Join(fields[1:], " "),
+					})
+
+				case "open", "close", "lock", "unlock":
+					if len(fields) == 1 {
+						u.Printf("%s in which direction?\n", fields[0])
+						continue eventLoop
+					}
+					d, found := nav[fields[1]]
+					if !found {
+						u.Printf("%s is not a direction\n", fields[1])
+						continue eventLoop
+					}
+					send <- NewMessage(&EventDoor{
+						DoorAction: DoorAction(fields[0]),
+						Direction:  d,
+					})
+
 				case "p", "pickup":
 					if len(fields) == 1 {
 						u.Println("pickup what?")
@@ -181,6 +305,9 @@ func (u *UI) HandleEvent(e interface{}) {
 	switch e := e.(type) {
 	case *EventInventoryUpdate:
 		u.Character.Inventory = *e.Inventory
+		if e.Note != "" {
+			u.Println(e.Note)
+		}
 
 	case *EventGoAway:
 		u.OtherPlayer(e.Name, "went away")
@@ -197,6 +324,7 @@ func (u *UI) HandleEvent(e interface{}) {
 	case *EventJoinGame:
 		// when you coin
 		u.Character = *e.Character
+		u.visited[e.Character.Location] = true
 		u.Location = fmt.Sprintf("%s/%s", e.Title, e.Location.Tile)
 		u.Write(Center(
 			[]byte(
@@ -217,9 +345,21 @@ func (u *UI) HandleEvent(e interface{}) {
 			u.Println()
 		}
 		for _, item := range e.Loose {
-			u.Write(Center([]byte("You found a " + item.Name + "!")))
+			line := "You found a " + string(item.Name) + "!"
+			if len(item.Contents) > 0 {
+				line += " It holds " + contents(item.Contents)
+			}
+			u.Write(Center(line))
+		}
+		u.showVendors(e.Tile.Vendors)
+		if b := e.Tile.NewsBoard; b != nil && len(b.Headlines) > 0 {
+			news := b.Headlines
+			if len(news) > 3 {
+				news = news[:3]
+			}
+			u.showNews(news)
 		}
-		u.showNav(&e.Tile.Nav)
+		u.showNav(&e.Tile)
 		u.Println()
 
 	case *EventExamine:
@@ -238,17 +378,95 @@ func (u *UI) HandleEvent(e interface{}) {
 			u.Write(Center(buf.Bytes()))
 			u.Println()
 		}
+		if e.Def != nil {
+			u.showItemDef(e.Def)
+		}
+		if len(e.Item.Contents) > 0 {
+			u.Write(Indent("It holds " + contents(e.Item.Contents)))
+			u.Println()
+			u.Println()
+		}
 
 	case *EventMove:
-		if u.Character.Location.Equal(e.Location) {
-			u.Println("cannot move in that direction")
+		if e.Note != "" {
+			u.Println(e.Note)
 			return
 		}
 		u.Character.Location = e.Location
+		u.visited[e.Location] = true
 		u.showTile(e.Tile, false)
 		u.Println()
+		if u.miniMap && u.areaMap != nil && u.areaMap.Ident == e.Location.Area {
+			u.Println()
+			u.Write(Center(u.renderMap(1)))
+			u.Println()
+		}
 		u.Location = fmt.Sprintf("%s/%s", e.Title, e.Location.Tile)
 
+	case *EventMap:
+		u.areaMap = e.AreaMap
+		u.Println()
+		u.Write(Center(Boxed(CenterIn([]byte(e.AreaMap.Title), 36), 40)))
+		u.Println()
+		u.Println()
+		u.Write(Center(u.renderMap(0)))
+		u.Println()
+		u.Println()
+		u.Write(Center("@ you are here, ? not visited, # closed door"))
+		u.Println()
+
+	case *EventStatus:
+		if e.Note != "" {
+			u.Println()
+			u.Write(Center(e.Note))
+			u.Println()
+			return
+		}
+		u.showStatus(e.Name, &e.Stats)
+
+	case *EventAttack:
+		u.Println(e.Note)
+
+	case *EventQuests:
+		u.showQuests(e.Quests)
+
+	case *EventQuestUpdate:
+		u.Printf("\n[%s] %s\n", e.Title, e.Note)
+
+	case *EventNotice:
+		u.Println(e.Text)
+
+	case *EventReadNews:
+		if e.Note != "" {
+			u.Println(e.Note)
+			return
+		}
+		u.showNews(e.Headlines)
+
+	case *EventPostNews:
+		u.Println(e.Note)
+
+	case *EventCombat:
+		u.showCombat(e)
+
+	case *EventDoor:
+		u.Println(e.Note)
+
+	case *EventUse:
+		u.Println(e.Note)
+
+	case *EventEquip:
+		u.Println(e.Note)
+
+	case *EventUnequip:
+		u.Println(e.Note)
+
+	case *EventBroadcast:
+		fmt.Fprintf(u.IO, "\n [channel] %s: %s\n", e.Name, e.Text)
+
+	case *EventGoto:
+		u.Println(e.Note)
+
 	case *EventPickup:
 		if !e.ItemFound {
 			u.Printf("there is no %s\n", e.Item.Name)
@@ -269,15 +487,152 @@ func (u *UI) showInventory() {
 	u.Println()
 	var buf bytes.Buffer
 	for i, item := range u.Character.Inventory.Items {
+		name := title(item.Name)
+		if item.Equipped {
+			name += " (equipped)"
+		}
+		if len(item.Contents) > 0 {
+			name += " with " + contents(item.Contents)
+		}
 		switch {
 		case item.Count > 1:
-			buf.WriteString(fmt.Sprintf("%v. %v %-30s\n", i+1, item.Count, item.Name))
+			buf.WriteString(fmt.Sprintf("%v. %v %-30s\n", i+1, item.Count, name))
 		case item.Count == 1:
-			buf.WriteString(fmt.Sprintf("%v. %-30s\n", i+1, item.Name))
+			buf.WriteString(fmt.Sprintf("%v. %-30s\n", i+1, name))
+		}
+	}
+	u.Write(Indent(buf.Bytes()))
+	u.Println()
+}
+
+func (u *UI) renderMap(radius int) []byte {
+	loc := u.Character.Location
+	visited := func(id Ident) bool {
+		return u.visited[Location{Area: u.areaMap.Ident, Tile: id}]
+	}
+	return RenderMap(u.areaMap, loc.Tile, visited, radius)
+}
+
+func (u *UI) showNews(news []Headline) {
+	u.Println()
+	var buf bytes.Buffer
+	for _, h := range news {
+		buf.WriteString(h.Time.Format("15:04") + " ")
+		if h.Author != "" {
+			buf.WriteString(string(h.Author) + ": ")
+		}
+		buf.WriteString(h.Text + "\n")
+	}
+	u.Write(Indent(bytes.TrimSpace(buf.Bytes())))
+	u.Println()
+}
+
+func (u *UI) showQuests(quests []QuestStatus) {
+	u.Println()
+	u.Write(Center(Boxed(CenterIn([]byte("Quests"), 36), 40)))
+	u.Println()
+	u.Println()
+	var buf bytes.Buffer
+	for _, q := range quests {
+		buf.WriteString(string(q.Title))
+		if q.Completed {
+			buf.WriteString(" (completed)")
+		}
+		buf.WriteString("\n")
+		for _, o := range q.Objectives {
+			mark := " "
+			if o.Done {
+				mark = "x"
+			}
+			buf.WriteString(fmt.Sprintf("  [%s] %s\n", mark, o.Short))
+		}
+		buf.WriteString("\n")
+	}
+	u.Write(Indent(bytes.TrimSpace(buf.Bytes())))
+	u.Println()
+	u.Println()
+}
+
+func (u *UI) showCombat(e *EventCombat) {
+	me := u.Character.Name
+	attacker, defender := string(e.Attacker), string(e.Defender)
+	switch {
+	case e.Attacker == me:
+		attacker = "You"
+	case e.Defender == me:
+		defender = "you"
+	}
+	u.Printf("%s hit %s for %v damage", attacker, defender, e.Damage)
+	if e.Defeated {
+		u.Printf(", %s is defeated!\n", defender)
+		return
+	}
+	u.Printf(" (%v health left)\n", e.Health)
+}
+
+func (u *UI) showStatus(name Name, s *Stats) {
+	u.Println()
+	u.Write(Center(Boxed(CenterIn([]byte(name), 36), 40)))
+	u.Println()
+	u.Println()
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("Level      %v (%v/%v xp)\n", s.Level, s.Experience, s.NextLevel()))
+	buf.WriteString(fmt.Sprintf("Health     %v/%v\n", s.Health, s.MaxHealth))
+	buf.WriteString(fmt.Sprintf("Energy     %v/%v\n", s.Energy, s.MaxEnergy))
+	buf.WriteString(fmt.Sprintf("Strength   %v\n", s.Strength))
+	buf.WriteString(fmt.Sprintf("Defense    %v\n", s.Defense))
+	for skill, v := range s.Skills {
+		buf.WriteString(fmt.Sprintf("%-10s %v\n", title(skill), v))
+	}
+	u.Write(Indent(buf.Bytes()))
+	u.Println()
+	u.Println()
+}
+
+func (u *UI) showVendors(vendors Vendors) {
+	for _, v := range vendors {
+		var buf bytes.Buffer
+		buf.WriteString(title(Name(v.Short)) + "\n")
+		for _, o := range v.Offers {
+			buf.WriteString(fmt.Sprintf("  %-20s %4v credits", o.Name, o.Price))
+			if o.Stock == 0 {
+				buf.WriteString(" (sold out)")
+			}
+			buf.WriteString("\n")
 		}
+		u.Println()
+		u.Println()
+		u.Write(Indent(buf.Bytes()))
+	}
+}
+
+func (u *UI) showItemDef(def *ItemDef) {
+	u.Println()
+	u.Write(Center(Boxed(CenterIn([]byte(title(def.Name)), 36), 40)))
+	u.Println()
+	u.Println()
+	var buf bytes.Buffer
+	if def.Description != "" {
+		buf.WriteString(def.Description)
+		buf.WriteString("\n\n")
+	}
+	buf.WriteString(fmt.Sprintf("weight %vg, value %v credits", def.Weight, def.Value))
+	var props []string
+	if def.Stackable {
+		props = append(props, "stackable")
+	}
+	if def.Usable {
+		props = append(props, "usable")
+	}
+	if def.Tradeable {
+		props = append(props, "tradeable")
+	}
+	if len(props) > 0 {
+		buf.WriteString("\n" + strings.Join(props, ", "))
 	}
 	u.Write(Indent(buf.Bytes()))
 	u.Println()
+	u.Println()
 }
 
 func (u *UI) showUsage() {
@@ -305,19 +660,22 @@ func (u *UI) showTile(t *Tile, long bool) {
 	}
 }
 
-func (u *UI) showNav(n *Nav) {
+func (u *UI) showNav(t *Tile) {
 	u.Println()
 	u.Println()
-	u.Write(Indent(exits(*n)))
+	u.Write(Indent(exits(t.Nav, t.Doors)))
 	u.Println()
 	u.Println()
 }
 
-func exits(n Nav) []byte {
+func exits(n Nav, doors Doors) []byte {
 	var buf bytes.Buffer
 	for d, loc := range n {
 		if loc != "" {
 			buf.WriteString(Direction(d).String())
+			if door := doors.Door(Direction(d)); door != nil {
+				buf.WriteString("[" + door.String() + "]")
+			}
 			buf.WriteString(" ")
 		}
 	}
@@ -403,6 +761,28 @@ func frameLine(width int) string {
 	return buf.String()
 }
 
+// contents returns a comma separated list of items
+func contents(items Items) string {
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Count > 1 {
+			res = append(res, fmt.Sprintf("%v %s", item.Count, item.Name))
+			continue
+		}
+		res = append(res, string(item.Name))
+	}
+	return strings.Join(res, ", ")
+}
+
+// title returns the name with upper case first letter
+func title(n Name) string {
+	v := string(n)
+	if v == "" {
+		return v
+	}
+	return strings.ToUpper(v[:1]) + v[1:]
+}
+
 // ----------------------------------------
 
 var nav = map[string]Direction{
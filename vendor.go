@@ -0,0 +1,79 @@
+package cible
+
+import (
+	"fmt"
+	"time"
+)
+
+// Vendor sells items for credits and buys back what it offers, for
+// half the price.
+type Vendor struct {
+	Short // e.g. drink dispenser
+	Offers
+
+	// RestockEvery adds one item to each offer that is below max
+	RestockEvery time.Duration
+
+	lastRestock time.Time
+}
+
+// Offer returns the offer for the named item, nil if none.
+func (me *Vendor) Offer(n Name) *Offer {
+	for _, o := range me.Offers {
+		if lower(o.Name) == lower(n) {
+			return o
+		}
+	}
+	return nil
+}
+
+// Restock tops up offers if it's time to do so.
+func (me *Vendor) Restock(now time.Time) {
+	if me.lastRestock.IsZero() {
+		me.lastRestock = now
+		return
+	}
+	if me.RestockEvery <= 0 || now.Sub(me.lastRestock) < me.RestockEvery {
+		return
+	}
+	me.lastRestock = now
+	for _, o := range me.Offers {
+		if o.Stock < o.Max {
+			o.Stock++
+		}
+	}
+}
+
+type Offers []*Offer
+
+type Offer struct {
+	Name  // of item
+	Price uint
+
+	Stock uint
+	Max   uint // restocked up to
+}
+
+// BuyBack is what the vendor pays for one item.
+func (me *Offer) BuyBack() uint {
+	if me.Price < 2 {
+		return me.Price
+	}
+	return me.Price / 2
+}
+
+// Vendors of a tile
+type Vendors []*Vendor
+
+// Selling returns the vendor offering the named item.
+func (me Vendors) Selling(n Name) (*Vendor, *Offer, error) {
+	for _, v := range me {
+		if o := v.Offer(n); o != nil {
+			return v, o, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no one here trades %s", n)
+}
+
+// Credit is the name of the item used as currency
+const Credit Name = "credit"
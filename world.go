@@ -3,16 +3,54 @@ package cible
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 func NewWorld() World {
 	return World{
-		Areas: Areas{Spaceport()},
+		Areas:   Areas{Spaceport()},
+		Catalog: SpaceportItems(),
+		Spawns:  SpaceportSpawns(),
+		NPCs:    SpaceportNPCs(),
+		Quests:  SpaceportQuests(),
 	}
 }
 
 type World struct {
 	Areas
+	Catalog
+	Spawns
+	NPCs
+	Quests
+
+	areas map[Ident]*Area // index of Areas
+}
+
+// Area returns the area with the given ident, faster than
+// Areas.Area.
+func (w *World) Area(id Ident) (*Area, error) {
+	if a, found := w.areas[id]; found && a.Ident == id {
+		return a, nil
+	}
+	// not indexed yet, e.g. appended to Areas
+	a, err := w.Areas.Area(id)
+	if err != nil {
+		return nil, err
+	}
+	if w.areas == nil {
+		w.areas = make(map[Ident]*Area)
+	}
+	w.areas[id] = a
+	return a, nil
+}
+
+// indexAreas indexes all areas, after which Area is safe for
+// concurrent use.
+func (w *World) indexAreas() {
+	w.areas = make(map[Ident]*Area, len(w.Areas))
+	for _, a := range w.Areas {
+		w.areas[a.Ident] = a
+	}
 }
 
 type Areas []*Area
@@ -30,9 +68,19 @@ type Area struct {
 	Ident
 	Title
 	Tiles
+
+	Reset ResetPolicy
+
+	lastReset    time.Time
+	initialDoors map[*Door]Door
+	tiles        map[Ident]*Tile // index of Tiles
 }
 
 func (a *Area) Tile(id Ident) (*Tile, error) {
+	if t, found := a.tiles[id]; found && t.Ident == id {
+		return t, nil
+	}
+	// not added using AddTile or ident changed
 	for _, t := range a.Tiles {
 		if t.Ident == id {
 			return t, nil
@@ -42,10 +90,71 @@ func (a *Area) Tile(id Ident) (*Tile, error) {
 }
 
 func (a *Area) AddTile(tiles ...*Tile) {
+	if a.tiles == nil {
+		a.tiles = make(map[Ident]*Tile)
+	}
 	for _, t := range tiles {
 		a.Tiles = append(a.Tiles, t)
 		t.Ident.SetIdent(fmt.Sprintf("t%d", len(a.Tiles)))
+		a.tiles[t.Ident] = t
+	}
+}
+
+// FindTile returns the tile with the given ident or short name,
+// case insensitive.
+func (a *Area) FindTile(v string) (*Tile, error) {
+	for _, t := range a.Tiles {
+		if strings.EqualFold(string(t.Ident), v) ||
+			strings.EqualFold(string(t.Short), v) {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("tile %q not found", v)
+}
+
+// Path returns the shortest sequence of directions leading from one
+// tile to another. Links through doors that are not passable are
+// ignored.
+func (a *Area) Path(from, to Ident) ([]Direction, error) {
+	type step struct {
+		prev Ident
+		Direction
+	}
+	visited := map[Ident]step{from: {}}
+	queue := []Ident{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == to {
+			var path []Direction
+			for id != from {
+				s := visited[id]
+				path = append([]Direction{s.Direction}, path...)
+				id = s.prev
+			}
+			return path, nil
+		}
+		t, err := a.Tile(id)
+		if err != nil {
+			return nil, err
+		}
+		for d, next := range t.Nav {
+			if next == "" {
+				continue
+			}
+			if _, found := visited[next]; found {
+				continue
+			}
+			if door := t.Doors.Door(Direction(d)); door != nil {
+				if door.Passable() != nil {
+					continue
+				}
+			}
+			visited[next] = step{prev: id, Direction: Direction(d)}
+			queue = append(queue, next)
+		}
 	}
+	return nil, fmt.Errorf("no route from %s to %s", from, to)
 }
 
 type Tiles []*Tile
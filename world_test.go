@@ -1,6 +1,9 @@
 package cible
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestAreas(t *testing.T) {
 	a := Areas{
@@ -20,3 +23,69 @@ func TestAreas(t *testing.T) {
 func TestTile(t *testing.T) {
 	_ = Spaceport()
 }
+
+func TestArea_Path(t *testing.T) {
+	a := Spaceport()
+	// the rest room door is locked, so go around
+	path, err := a.Path("t1", "t8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprint(path)
+	if exp := "[S E N]"; got != exp {
+		t.Errorf("got %s, expected %s", got, exp)
+	}
+	if _, err := a.Path("t1", "x"); err == nil {
+		t.Error("found path to missing tile")
+	}
+}
+
+func TestArea_FindTile(t *testing.T) {
+	a := Spaceport()
+	for _, v := range []string{"t6", "news room", "News Room"} {
+		if _, err := a.FindTile(v); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := a.FindTile("x"); err == nil {
+		t.Error("found missing tile")
+	}
+}
+
+func BenchmarkArea_Tile_5000(b *testing.B) {
+	a := &Area{Ident: "a1"}
+	for i := 0; i < 5000; i++ {
+		a.AddTile(&Tile{})
+	}
+	ids := make([]Ident, 0, len(a.Tiles))
+	for _, t := range a.Tiles {
+		ids = append(ids, t.Ident)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := a.Tile(ids[i%len(ids)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGame_Place_1000_areas(b *testing.B) {
+	g := NewGame()
+	locs := make([]Location, 0, 5000)
+	for i := 0; i < 1000; i++ {
+		a := &Area{Ident: Ident(fmt.Sprintf("a%d", i+2))}
+		for j := 0; j < 5; j++ {
+			a.AddTile(&Tile{})
+		}
+		for _, t := range a.Tiles {
+			locs = append(locs, Location{Area: a.Ident, Tile: t.Ident})
+		}
+		g.Areas = append(g.Areas, a)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := g.Place(locs[i%len(locs)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}